@@ -0,0 +1,154 @@
+package runner
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/pkg/crawler"
+	"github.com/projectdiscovery/nuclei/pkg/executor"
+)
+
+// enrichInput discovers urls from the targets, writing them to a new
+// file used as input by the requests scanning the discovered urls.
+//
+// The discovered urls are kept apart from the targets as there can be
+// up to the maximum number of urls of the crawler per target and
+// discovery method, which would multiply the requests of every template.
+func (r *Runner) enrichInput() error {
+	if !r.options.Crawl && !r.options.SeedPaths && !r.options.JSAnalysis && !r.options.JSEndpoints {
+		return nil
+	}
+
+	// Connect to the targets like the executors do, the requests of
+	// the crawler having their own budget per host
+	transport, err := executor.NewTransport(&executor.TransportOptions{
+		Timeout:       r.options.Timeout,
+		ProxyURL:      r.options.ProxyURL,
+		ProxySocksURL: r.options.ProxySocksURL,
+		ProxyAuth:     r.options.ProxyAuth,
+		Resolver:      r.resolver,
+		Dialer:        r.makeDialerOptions(),
+	})
+	if err != nil {
+		return err
+	}
+	var budget *executor.RequestBudget
+	if r.options.RequestBudget > 0 {
+		budget = r.newRequestBudget(r.options.RequestBudget)
+	}
+
+	crawlerOptions := crawler.DefaultOptions
+	crawlerOptions.Depth = r.options.CrawlDepth
+	crawlerOptions.Timeout = r.options.Timeout
+	crawlerOptions.Transport = executor.NewLimitedTransport(transport, budget, r.throttle)
+	urlCrawler := crawler.New(&crawlerOptions)
+
+	input, err := os.Open(r.inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	discovered, err := ioutil.TempFile("", "discovered-input-*")
+	if err != nil {
+		return err
+	}
+	defer discovered.Close()
+
+	writer := bufio.NewWriter(discovered)
+	mutex := &sync.Mutex{}
+	// The urls are deduplicated across all the targets, except in bounded
+	// mode where they are only deduplicated per target instead of keeping
+	// all of them in memory
+	allSeen := make(map[string]struct{})
+	makeSeen := func() map[string]struct{} {
+		if r.options.Bounded {
+			return make(map[string]struct{})
+		}
+		return allSeen
+	}
+	write := func(URLs []string, seen map[string]struct{}) {
+		mutex.Lock()
+		for _, URL := range URLs {
+			if _, ok := seen[URL]; ok {
+				continue
+			}
+			seen[URL] = struct{}{}
+			writer.WriteString(URL)
+			writer.WriteRune('\n')
+		}
+		mutex.Unlock()
+	}
+
+	limiter := make(chan struct{}, r.options.Threads)
+	wg := &sync.WaitGroup{}
+
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		target := strings.TrimSpace(scanner.Text())
+		if target == "" {
+			continue
+		}
+		// The targets themselves are already scanned
+		seen := makeSeen()
+		mutex.Lock()
+		seen[target] = struct{}{}
+		mutex.Unlock()
+
+		limiter <- struct{}{}
+		wg.Add(1)
+
+		go func(target string, seen map[string]struct{}) {
+			defer wg.Done()
+			defer func() { <-limiter }()
+
+			if r.options.SeedPaths {
+				URLs, err := urlCrawler.Seed(target)
+				if err != nil {
+					gologger.Warningf("Could not seed paths of %s: %s\n", target, err)
+				} else {
+					gologger.Verbosef("Discovered %d urls from robots.txt and sitemaps of %s\n", "seed", len(URLs), target)
+					write(URLs, seen)
+				}
+			}
+			if r.options.Crawl {
+				URLs, err := urlCrawler.Crawl(target)
+				if err != nil {
+					gologger.Warningf("Could not crawl %s: %s\n", target, err)
+				} else {
+					gologger.Verbosef("Discovered %d urls from %s\n", "crawl", len(URLs), target)
+					write(URLs, seen)
+				}
+			}
+			if r.options.JSAnalysis || r.options.JSEndpoints {
+				scripts, endpoints, err := urlCrawler.Scripts(target)
+				if err != nil {
+					gologger.Warningf("Could not analyze javascript files of %s: %s\n", target, err)
+				} else {
+					gologger.Verbosef("Discovered %d javascript files and %d endpoints from %s\n", "js", len(scripts), len(endpoints), target)
+					if r.options.JSAnalysis {
+						write(scripts, seen)
+					}
+					if r.options.JSEndpoints {
+						write(endpoints, seen)
+					}
+				}
+			}
+		}(target, seen)
+	}
+	wg.Wait()
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	r.discoveredFile = discovered.Name()
+	return nil
+}
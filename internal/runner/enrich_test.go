@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/pkg/executor"
+	"github.com/projectdiscovery/nuclei/pkg/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrichInputDiscovered(t *testing.T) {
+	mutex := &sync.Mutex{}
+	paths := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		paths[r.URL.Path]++
+		mutex.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/a">a</a>`)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "nuclei-enrich-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "targets.txt")
+	require.Nil(t, ioutil.WriteFile(input, []byte(server.URL), 0644), "Could not write targets")
+
+	r := &Runner{inputFile: input, options: &Options{Crawl: true, CrawlDepth: 2, Timeout: 5, Threads: 2}}
+	require.Nil(t, r.enrichInput(), "Could not enrich input")
+	defer os.Remove(r.discoveredFile)
+	require.Equal(t, input, r.inputFile, "Could not keep targets as input")
+
+	data, err := ioutil.ReadFile(r.discoveredFile)
+	require.Nil(t, err, "Could not read discovered urls")
+	require.Equal(t, server.URL+"/a\n", string(data), "Could not write discovered urls apart from the targets")
+
+	r.outputWriter = executor.NewOutputWriter(&executor.OutputOptions{})
+	defer r.outputWriter.Close()
+	run := func(discovered bool) map[string]int {
+		path := filepath.Join(dir, "template.yaml")
+		template := fmt.Sprintf(`id: test
+info:
+  name: test
+  author: test
+requests:
+  - method: GET
+    discovered: %t
+    path:
+      - "{{BaseURL}}"
+`, discovered)
+		require.Nil(t, ioutil.WriteFile(path, []byte(template), 0644), "Could not write template")
+		parsed, err := templates.ParseTemplate(path)
+		require.Nil(t, err, "Could not parse template")
+
+		mutex.Lock()
+		paths = make(map[string]int)
+		mutex.Unlock()
+		r.processTemplateRequest(parsed, parsed.RequestsHTTP[0], nil)
+		return paths
+	}
+	require.Equal(t, map[string]int{"/": 1}, run(false), "Could not only scan targets")
+	require.Equal(t, map[string]int{"/": 1, "/a": 1}, run(true), "Could not scan discovered urls")
+}
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// healthServer serves the health of the scan on /healthz for the
+// service managers and load balancers monitoring it.
+type healthServer struct {
+	mutex  *sync.Mutex
+	server *http.Server
+	// progress is the last progress event of the scan, if any
+	progress *progressEvent
+}
+
+// healthStatus is the health of the scan served on /healthz
+type healthStatus struct {
+	// Status is ok as long as the process is serving requests
+	Status string `json:"status"`
+	// Progress is the last progress event of the scan, if any
+	Progress *progressEvent `json:"progress,omitempty"`
+}
+
+// newHealthServer starts serving the health of the scan on an address
+func newHealthServer(address string) (*healthServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &healthServer{mutex: &sync.Mutex{}}
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", health)
+	health.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := health.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			gologger.Warningf("Could not serve health endpoint: %s\n", err)
+		}
+	}()
+	return health, nil
+}
+
+// ServeHTTP serves the health of the scan
+func (h *healthServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	status := &healthStatus{Status: "ok", Progress: h.progress}
+	h.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// setProgress records the last progress event of the scan.
+//
+// A nil health server ignores the events.
+func (h *healthServer) setProgress(event *progressEvent) {
+	if h == nil {
+		return
+	}
+
+	h.mutex.Lock()
+	h.progress = event
+	h.mutex.Unlock()
+}
+
+// Close stops the server
+func (h *healthServer) Close() {
+	if h == nil {
+		return
+	}
+	shutdownServer(h.server)
+}
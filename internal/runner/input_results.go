@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/pkg/executor"
+)
+
+// readResultsInput reads the matched targets from a previous json output
+// and writes the unique ones to the writer, one per line.
+//
+// If templates is not empty, only the results of the comma separated
+// template ids are used. If unique is false, the targets are written
+// as they are read without keeping them in memory to skip duplicates.
+func readResultsInput(file, templates string, unique bool, writer io.Writer) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	allowed := make(map[string]struct{})
+	for _, template := range strings.Split(templates, ",") {
+		if template = strings.TrimSpace(template); template != "" {
+			allowed[template] = struct{}{}
+		}
+	}
+
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		result := &executor.Result{}
+		if err := json.Unmarshal([]byte(text), result); err != nil {
+			return err
+		}
+		// Skip the records which aren't findings, such as blind xss injections
+		if !result.IsFinding() {
+			continue
+		}
+		if len(allowed) > 0 {
+			if _, ok := allowed[result.Template]; !ok {
+				continue
+			}
+		}
+		if result.Matched == "" {
+			continue
+		}
+		if unique {
+			if _, ok := seen[result.Matched]; ok {
+				continue
+			}
+			seen[result.Matched] = struct{}{}
+		}
+
+		if _, err := io.WriteString(writer, result.Matched+"\n"); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
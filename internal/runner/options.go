@@ -5,23 +5,54 @@ import (
 	"os"
 
 	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/pkg/executor"
 )
 
 // Options contains the configuration options for tuning
 // the template requesting process.
 type Options struct {
-	Templates     string // Signature specifies the template/templates to use
-	Targets       string // Targets specifies the targets to scan using templates.
-	Threads       int    // Thread controls the number of concurrent requests to make.
-	Timeout       int    // Timeout is the seconds to wait for a response from the server.
-	Retries       int    // Retries is the number of times to retry the request
-	Output        string // Output is the file to write found subdomains to.
-	ProxyURL      string // ProxyURL is the URL for the proxy server
-	ProxySocksURL string // ProxySocksURL is the URL for the proxy socks server
-	Silent        bool   // Silent suppresses any extra text and only writes found URLs on screen.
-	Version       bool   // Version specifies if we should just show version and exit
-	Verbose       bool   // Verbose flag indicates whether to show verbose output or not
-	NoColor       bool   // No-Color disables the colored output.
+	Templates       string // Signature specifies the template/templates to use
+	RulePacks       string // RulePacks is a comma separated list of secret detection rule packs to run as templates.
+	Targets         string // Targets specifies the targets to scan using templates.
+	InputResults    string // InputResults is a previous json output to use the matched targets of as input.
+	ResultsFilter   string // ResultsFilter is a comma separated list of template ids to use the input results of.
+	Threads         int    // Thread controls the number of concurrent requests to make.
+	AdaptiveMax     int    // AdaptiveMax enables adapting the concurrency to the error rates, up to the value.
+	Timeout         int    // Timeout is the seconds to wait for a response from the server.
+	Retries         int    // Retries is the number of times to retry the request
+	ConnectTimeout  int    // ConnectTimeout is the seconds to wait for a connection to be established.
+	KeepAlive       int    // KeepAlive is the seconds between the tcp keep-alive probes, negative to disable.
+	NoHappyEyeballs bool   // NoHappyEyeballs disables racing ipv4 and ipv6 connections to dual-stack hosts.
+	RequestBudget   int    // RequestBudget is the maximum number of requests a template can send to each host.
+	Output          string // Output is the file to write found subdomains to.
+	ExtractOutput   string // ExtractOutput is the directory to write the extracted values to, a file per extractor.
+	ProxyURL        string // ProxyURL is the URL for the proxy server
+	ProxySocksURL   string // ProxySocksURL is the URL for the proxy socks server
+	ProxyAuth       string // ProxyAuth is the authentication scheme of the http proxy, basic or ntlm.
+	DoHURL          string // DoHURL is the URL of the DNS-over-HTTPS/TLS resolver
+	BlindXSS        string // BlindXSS is the yaml file containing the blind xss payload configuration.
+	BlindXSSLog     string // BlindXSSLog is the file recording the requests injected with the blind xss payload.
+	EnvVars         string // EnvVars is a comma separated list of environment variables templates are allowed to use.
+	SpoofProfile    string // SpoofProfile is the name of the ip spoofing header profile to use.
+	StreamAddr      string // StreamAddr is the address to stream the scan events on using server-sent events.
+	HealthAddr      string // HealthAddr is the address to serve the health of the scan on /healthz.
+	StateFile       string // StateFile is the file persisting the progress of the scan to resume it.
+	Silent          bool   // Silent suppresses any extra text and only writes found URLs on screen.
+	Version         bool   // Version specifies if we should just show version and exit
+	Verbose         bool   // Verbose flag indicates whether to show verbose output or not
+	NoColor         bool   // No-Color disables the colored output.
+	NoThrottle      bool   // NoThrottle disables slowing down the requests to hosts blocking the scan.
+	JSON            bool   // JSON writes the results as json lines.
+	TemplatesJSON   bool   // TemplatesJSON writes the metadata of the templates as json and exits.
+	Test            bool   // Test verifies the templates against their fixture files and exits.
+	Crawl           bool   // Crawl discovers urls from the targets for the discovered requests.
+	CrawlDepth      int    // CrawlDepth is the maximum number of links followed from each target.
+	SeedPaths       bool   // SeedPaths discovers the urls listed in the robots.txt and sitemaps of the targets.
+	JSAnalysis      bool   // JSAnalysis discovers the javascript files linked from the targets.
+	JSEndpoints     bool   // JSEndpoints discovers the endpoints found in the javascript files of the targets.
+	SkipDeprecated  bool   // SkipDeprecated skips the templates marked as deprecated instead of warning about them.
+	Priority        bool   // Priority runs the templates with the highest priority and severity first.
+	Bounded         bool   // Bounded scans with bounded memory, streaming the templates and spilling the output on disk.
 
 	Stdin bool // Stdin specifies whether stdin input was given to the process
 }
@@ -31,17 +62,47 @@ func ParseOptions() *Options {
 	options := &Options{}
 
 	flag.StringVar(&options.Templates, "t", "", "Template input file/files to run on host")
+	flag.StringVar(&options.RulePacks, "rule-packs", "", "Comma separated Gitleaks (.toml) or TruffleHog (.json) rule packs to scan for secrets")
 	flag.StringVar(&options.Targets, "l", "", "List of URLs to run templates on")
+	flag.StringVar(&options.InputResults, "input-results", "", "Previous json output to use the matched targets of as input")
+	flag.StringVar(&options.ResultsFilter, "results-templates", "", "Comma separated template ids to use the input results of (optional)")
 	flag.StringVar(&options.Output, "o", "", "File to write output to (optional)")
+	flag.StringVar(&options.ExtractOutput, "extract-output", "", "Directory to write the unique extracted values to, a file per extractor name (optional)")
 	flag.StringVar(&options.ProxyURL, "proxy-url", "", "URL of the proxy server")
 	flag.StringVar(&options.ProxySocksURL, "proxy-socks-url", "", "URL of the proxy socks server")
+	flag.StringVar(&options.ProxyAuth, "proxy-auth", executor.ProxyAuthBasic, "Authentication scheme of the proxy server (basic, ntlm)")
+	flag.StringVar(&options.DoHURL, "doh-url", "", "URL of the DNS-over-HTTPS (https://) or DNS-over-TLS (tls://) resolver")
+	flag.StringVar(&options.BlindXSS, "blindxss-config", "", "YAML file containing the blind xss payload to inject in {{blindxss}}")
+	flag.StringVar(&options.BlindXSSLog, "blindxss-log", "", "File recording the requests injected with the blind xss payload and their correlation ids")
+	flag.StringVar(&options.EnvVars, "env-vars", "", "Comma separated environment variables templates can use with {{env \"NAME\"}}")
+	flag.StringVar(&options.SpoofProfile, "spoof-profile", "", "IP spoofing header profile to send (loopback, localhost, private, random)")
+	flag.StringVar(&options.StreamAddr, "stream-addr", "", "Address to stream results and progress on as server-sent events (eg. 127.0.0.1:8080)")
+	flag.StringVar(&options.HealthAddr, "health-addr", "", "Address to serve the health of the scan on /healthz (eg. 127.0.0.1:8081)")
+	flag.StringVar(&options.StateFile, "state-file", "", "File persisting the progress of the scan to resume it after a crash or restart")
 	flag.BoolVar(&options.Silent, "silent", false, "Show only results in output")
 	flag.BoolVar(&options.Version, "version", false, "Show version of nuclei")
 	flag.BoolVar(&options.Verbose, "v", false, "Show Verbose output")
 	flag.BoolVar(&options.NoColor, "nC", false, "Don't Use colors in output")
+	flag.BoolVar(&options.NoThrottle, "no-throttle", false, "Don't slow down requests to hosts rate limiting or blocking the scan")
+	flag.BoolVar(&options.JSON, "json", false, "Write output in JSON lines format")
+	flag.BoolVar(&options.TemplatesJSON, "templates-json", false, "Write the metadata of the templates as JSON and exit")
+	flag.BoolVar(&options.Test, "test", false, "Test the templates against their fixture files and exit")
+	flag.BoolVar(&options.Crawl, "crawl", false, "Crawl the targets to discover urls scanned by the discovered requests")
+	flag.IntVar(&options.CrawlDepth, "crawl-depth", 2, "Maximum number of links followed from each target when crawling")
+	flag.BoolVar(&options.SeedPaths, "seed-paths", false, "Discover urls from the robots.txt and sitemaps of the targets")
+	flag.BoolVar(&options.JSAnalysis, "js", false, "Discover the javascript files linked from the targets and on their hosts")
+	flag.BoolVar(&options.JSEndpoints, "js-endpoints", false, "Discover the endpoints found in the javascript files of the targets")
+	flag.BoolVar(&options.SkipDeprecated, "skip-deprecated", false, "Skip the templates marked as deprecated")
+	flag.BoolVar(&options.Priority, "priority", false, "Run templates by priority and severity, most important first")
+	flag.BoolVar(&options.Bounded, "bounded", false, "Scan with bounded memory for huge target lists")
 	flag.IntVar(&options.Threads, "c", 10, "Number of concurrent requests to make")
+	flag.IntVar(&options.AdaptiveMax, "adaptive-c", 0, "Adapt the concurrency to the error rates of the hosts, up to this maximum (0 to disable)")
 	flag.IntVar(&options.Timeout, "timeout", 5, "Time to wait in seconds before timeout")
 	flag.IntVar(&options.Retries, "retries", 1, "Number of times to retry a failed request")
+	flag.IntVar(&options.ConnectTimeout, "connect-timeout", 0, "Time to wait in seconds for a connection (default same as timeout)")
+	flag.IntVar(&options.KeepAlive, "tcp-keepalive", executor.DefaultKeepAlive, "Seconds between tcp keep-alive probes (negative to disable)")
+	flag.BoolVar(&options.NoHappyEyeballs, "no-happy-eyeballs", false, "Don't race ipv4 and ipv6 connections to dual-stack hosts")
+	flag.IntVar(&options.RequestBudget, "request-budget", 0, "Maximum number of requests a template can send to each host (0 for no limit)")
 
 	flag.Parse()
 
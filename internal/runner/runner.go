@@ -2,9 +2,11 @@ package runner
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,13 +19,55 @@ import (
 	"github.com/projectdiscovery/nuclei/pkg/templates"
 )
 
+// boundedMaxBodySize is the maximum number of bytes read from
+// each response in bounded mode.
+const boundedMaxBodySize = 2 * 1024 * 1024
+
+// boundedMaxBudgetHosts is the maximum number of hosts whose request
+// counts are kept by the request budgets in bounded mode.
+const boundedMaxBudgetHosts = 100000
+
 // Runner is a client for running the enumeration process.
 type Runner struct {
 	// output is the output file to write if any
-	output      *os.File
-	outputMutex *sync.Mutex
+	output *os.File
+	// outputWriter writes the results to the screen and output file
+	outputWriter *executor.OutputWriter
+	// extractWriter writes the extracted values to files, if asked
+	extractWriter *executor.ExtractWriter
 
 	tempFile string
+	// inputFile is the file containing the targets to scan
+	inputFile string
+	// discoveredFile is the file containing the urls discovered from
+	// the targets, if asked
+	discoveredFile string
+	// resolver is the secure dns resolver if any
+	resolver *executor.SecureResolver
+	// blindXSS is the blind xss payload configuration if any
+	blindXSS *executor.BlindXSS
+	// correlationLog records the requests injected with the blind xss payload
+	correlationLog *executor.CorrelationLog
+	// throttle slows down the requests to the hosts blocking the scan, if enabled
+	throttle *executor.HostThrottle
+	// concurrency adapts the concurrency to the error rates, if enabled
+	concurrency *executor.ConcurrencyController
+	// envVars contains the environment variables templates are allowed to use
+	envVars map[string]struct{}
+	// envValues contains the values of the allowed environment variables
+	envValues map[string]interface{}
+	// spoof is the ip spoofing header profile if any
+	spoof requests.SpoofProfile
+	// stream streams the scan events to clients if any
+	stream *eventStream
+	// health serves the health of the scan if asked
+	health *healthServer
+	// state persists the progress of the scan to resume it, if asked
+	state *scanState
+	// stopping is set once the scan was asked to stop
+	stopping int32
+	// service reports the scan state when running as a windows service
+	service *windowsService
 	// options contains configuration options for runner
 	options *Options
 }
@@ -31,8 +75,7 @@ type Runner struct {
 // New creates a new client for running enumeration process.
 func New(options *Options) (*Runner, error) {
 	runner := &Runner{
-		outputMutex: &sync.Mutex{},
-		options:     options,
+		options: options,
 	}
 
 	// If we have stdin, write it to a new file
@@ -48,45 +91,463 @@ func New(options *Options) (*Runner, error) {
 		tempInput.Close()
 	}
 
-	// Create the output file if asked
+	// If we have previous results, write their targets to a new file
+	if options.InputResults != "" {
+		tempInput, err := ioutil.TempFile("", "results-input-*")
+		if err != nil {
+			return nil, err
+		}
+		if err := readResultsInput(options.InputResults, options.ResultsFilter, !options.Bounded, tempInput); err != nil {
+			tempInput.Close()
+			os.Remove(tempInput.Name())
+			return nil, err
+		}
+		runner.tempFile = tempInput.Name()
+		tempInput.Close()
+	}
+
+	// Use the targets given by the user, or the ones we have written
+	if options.Targets != "" {
+		runner.inputFile = options.Targets
+	} else {
+		runner.inputFile = runner.tempFile
+	}
+
+	// Create the secure resolver if asked
+	if options.DoHURL != "" {
+		resolver, err := executor.NewSecureResolver(options.DoHURL, options.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		runner.resolver = resolver
+	}
+
+	// Load the blind xss payload configuration if asked
+	if options.BlindXSS != "" {
+		blindXSS, err := executor.LoadBlindXSS(options.BlindXSS)
+		if err != nil {
+			return nil, err
+		}
+		runner.blindXSS = blindXSS
+
+		correlationLog, err := executor.NewCorrelationLog(options.BlindXSSLog)
+		if err != nil {
+			return nil, err
+		}
+		runner.correlationLog = correlationLog
+	}
+
+	// Read the environment variables templates are allowed to use
+	var envVars []string
+	runner.envVars = make(map[string]struct{})
+	for _, name := range strings.Split(options.EnvVars, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			envVars = append(envVars, name)
+			runner.envVars[name] = struct{}{}
+		}
+	}
+	runner.envValues = requests.EnvValues(envVars)
+
+	// Get the ip spoofing header profile if asked
+	spoof, err := requests.GetSpoofProfile(options.SpoofProfile)
+	if err != nil {
+		return nil, err
+	}
+	runner.spoof = spoof
+
+	// Start streaming the scan events if asked
+	if options.StreamAddr != "" {
+		stream, err := newEventStream(options.StreamAddr)
+		if err != nil {
+			return nil, err
+		}
+		runner.stream = stream
+		gologger.Infof("Streaming events on http://%s/events\n", options.StreamAddr)
+	}
+
+	// Serve the health of the scan if asked
+	if options.HealthAddr != "" {
+		health, err := newHealthServer(options.HealthAddr)
+		if err != nil {
+			return nil, err
+		}
+		runner.health = health
+		gologger.Infof("Serving health on http://%s/healthz\n", options.HealthAddr)
+	}
+
+	// Resume the scan from its persisted state if asked
+	if options.StateFile != "" {
+		state, err := loadScanState(options.StateFile)
+		if err != nil {
+			return nil, err
+		}
+		if len(state.Completed) > 0 {
+			gologger.Infof("Resuming scan, skipping %d completed templates\n", len(state.Completed))
+		}
+		runner.state = state
+	}
+
+	// Create the output file if asked, keeping the results of
+	// the scan being resumed
 	if options.Output != "" {
-		output, err := os.Create(options.Output)
+		output, err := openOutputFile(options.Output, runner.state.isResumed())
 		if err != nil {
 			gologger.Fatalf("Could not create output file '%s': %s\n", options.Output, err)
 		}
 		runner.output = output
 	}
+	runner.outputWriter = runner.makeOutputWriter()
+
+	// Write the extracted values to a file per extractor if asked
+	if options.ExtractOutput != "" {
+		extractWriter, err := executor.NewExtractWriter(options.ExtractOutput)
+		if err != nil {
+			gologger.Fatalf("Could not create extract output directory '%s': %s\n", options.ExtractOutput, err)
+		}
+		runner.extractWriter = extractWriter
+	}
+
+	// Slow down the requests to hosts blocking the scan unless asked not to
+	if !options.NoThrottle {
+		runner.throttle = executor.NewHostThrottle(runner.onHostBlock)
+	}
+
+	runner.handleSignals()
+	runner.startService()
+
+	// Adapt the concurrency to the error rates if asked
+	if options.AdaptiveMax > 0 {
+		runner.concurrency = executor.NewConcurrencyController(options.Threads, options.AdaptiveMax, func(limit int) {
+			gologger.Verbosef("Adapted concurrency to %d\n", "concurrency", limit)
+		})
+	}
 	return runner, nil
 }
 
 // Close releases all the resources and cleans up
 func (r *Runner) Close() {
+	notifyService("STOPPING=1")
+	r.outputWriter.Close()
+	r.extractWriter.Close()
+	r.correlationLog.Close()
+	if r.stream != nil {
+		r.stream.Close()
+	}
+	r.health.Close()
 	r.output.Close()
 	os.Remove(r.tempFile)
+	if r.discoveredFile != "" {
+		os.Remove(r.discoveredFile)
+	}
+	r.stopService()
 }
 
 // RunEnumeration sets up the input layer for giving input nuclei.
 // binary and runs the actual enumeration
 func (r *Runner) RunEnumeration() {
-	// If the template path is a single template and not a glob, use that.
-	if !strings.Contains(r.options.Templates, "*") && strings.HasSuffix(r.options.Templates, ".yaml") {
-		template, err := templates.ParseTemplate(r.options.Templates)
+	matches := r.getTemplatePaths()
+
+	// Only test the templates against their fixtures if asked
+	if r.options.Test {
+		if failed := r.runTests(matches); failed > 0 {
+			gologger.Fatalf("%d template tests failed\n", failed)
+		}
+		return
+	}
+
+	// Tell the service manager the scan started, if any
+	if !r.options.TemplatesJSON {
+		notifyService("READY=1")
+		startWatchdog()
+	}
+
+	// In bounded mode the templates are run as they are parsed
+	// instead of being kept in memory for the whole scan.
+	bounded := r.options.Bounded && !r.options.TemplatesJSON
+	if bounded {
+		// Discover urls from the targets if asked
+		if err := r.enrichInput(); err != nil {
+			gologger.Fatalf("Could not enrich input: %s\n", err)
+		}
+		r.publishProgress(&progressEvent{Status: "started"})
+	}
+
+	var parsed []*templates.Template
+	metadata := []*templates.Metadata{}
+	r.parseTemplates(matches, func(template *templates.Template, path string) {
+		if bounded {
+			r.runTemplate(template)
+			return
+		}
+		parsed = append(parsed, template)
+		metadata = append(metadata, template.GetMetadata(path))
+	})
+
+	if !bounded {
+		// Only write the metadata of the templates if asked
+		if r.options.TemplatesJSON {
+			if err := json.NewEncoder(os.Stdout).Encode(metadata); err != nil {
+				gologger.Errorf("Could not write templates metadata: %s\n", err)
+			}
+			return
+		}
+
+		// Discover urls from the targets if asked
+		if err := r.enrichInput(); err != nil {
+			gologger.Fatalf("Could not enrich input: %s\n", err)
+		}
+
+		// Run the most important templates first if asked
+		if r.options.Priority {
+			templates.SortByPriority(parsed)
+		}
+
+		r.publishProgress(&progressEvent{Status: "started"})
+		for _, template := range parsed {
+			r.runTemplate(template)
+		}
+	}
+	r.publishProgress(&progressEvent{Status: "finished"})
+
+	// The state is only needed to resume an unfinished scan
+	if !r.isStopping() {
+		r.state.remove()
+	}
+
+	for _, stats := range r.throttle.Stats() {
+		gologger.Infof("Host %s blocked %d requests (paused: %t)\n", stats.Host, stats.Blocks, stats.Paused)
+	}
+	if r.concurrency != nil {
+		gologger.Infof("Finished with an adapted concurrency of %d\n", r.concurrency.Limit())
+	}
+}
+
+// parseTemplates parses the template files and the rule packs,
+// calling the callback with each template parsed along with its path.
+//
+// Parsing stops at the first template file which can't be parsed. The
+// deprecated templates are passed to the callback as well, so that they
+// are reported even when skipped, and their replacements are checked
+// once all the templates are parsed.
+func (r *Runner) parseTemplates(matches []string, callback func(template *templates.Template, path string)) {
+	ids := make(map[string]struct{})
+	var deprecated []*templates.Template
+	defer func() {
+		for _, template := range getUnknownReplacements(deprecated, ids) {
+			gologger.Labelf("[%s] Replacement template %s of deprecated template was not found\n", template.ID, template.ReplacedBy)
+		}
+	}()
+
+	for _, match := range matches {
+		template, err := templates.ParseTemplate(match)
+		if err != nil {
+			gologger.Errorf("Could not parse template file '%s': %s\n", match, err)
+			return
+		}
+		ids[template.ID] = struct{}{}
+		if template.Deprecated {
+			deprecated = append(deprecated, template)
+		}
+		callback(template, match)
+	}
+	for _, pack := range strings.Split(r.options.RulePacks, ",") {
+		if pack = strings.TrimSpace(pack); pack == "" {
+			continue
+		}
+		template, skipped, err := templates.ParseRulePack(pack)
+		for _, rule := range skipped {
+			gologger.Labelf("Could not compile rule '%s' of rule pack '%s', skipping it\n", rule, pack)
+		}
 		if err != nil {
-			gologger.Errorf("Could not parse template file '%s': %s\n", r.options.Templates, err)
+			gologger.Errorf("Could not parse rule pack '%s': %s\n", pack, err)
+			continue
+		}
+		ids[template.ID] = struct{}{}
+		callback(template, pack)
+	}
+}
+
+// runTemplate runs the requests of a template on all the targets
+func (r *Runner) runTemplate(template *templates.Template) {
+	if r.isStopping() {
+		return
+	}
+	if template.Deprecated {
+		if r.options.SkipDeprecated {
+			gologger.Verbosef("Skipping deprecated template\n", template.ID)
 			return
 		}
+		r.warnDeprecated(template)
+	}
+	if r.state.isCompleted(template.ID) {
+		gologger.Verbosef("Skipping template completed before resuming\n", template.ID)
+		return
+	}
+	// The env expressions would be sent as is without their variables
+	if !r.checkEnvVariables(template) {
+		gologger.Labelf("[%s] Skipping template using unavailable environment variables\n", template.ID)
+		return
+	}
+	r.publishProgress(&progressEvent{Status: "template", Template: template.ID})
+	notifyService("STATUS=Running template " + template.ID)
 
-		// process http requests
+	// The budget is shared by all the requests of the template
+	budget := r.makeRequestBudget(template)
+	if r.isSingleTemplate() {
 		for _, request := range template.RequestsHTTP {
-			r.processTemplateRequest(template, request)
+			r.processTemplateRequest(template, request, budget)
 		}
-
-		// process dns requests
 		for _, request := range template.RequestsDNS {
-			r.processTemplateRequest(template, request)
+			r.processTemplateRequest(template, request, budget)
 		}
+	} else {
+		for _, request := range template.RequestsDNS {
+			r.processTemplateRequest(template, request, budget)
+		}
+		for _, request := range template.RequestsHTTP {
+			r.processTemplateRequest(template, request, budget)
+		}
+	}
+
+	// Only record the templates run on all the targets
+	if !r.isStopping() {
+		if err := r.state.complete(template.ID); err != nil {
+			gologger.Warningf("Could not save scan state: %s\n", err)
+		}
+	}
+}
+
+// warnDeprecated warns about a deprecated template being run
+func (r *Runner) warnDeprecated(template *templates.Template) {
+	if template.ReplacedBy != "" {
+		gologger.Labelf("[%s] Template is deprecated, use %s instead\n", template.ID, template.ReplacedBy)
 		return
 	}
+	gologger.Labelf("[%s] Template is deprecated\n", template.ID)
+}
+
+// getUnknownReplacements returns the deprecated templates replaced
+// by templates which are not among the ids of the parsed templates.
+func getUnknownReplacements(deprecated []*templates.Template, ids map[string]struct{}) []*templates.Template {
+	var unknown []*templates.Template
+	for _, template := range deprecated {
+		if template.ReplacedBy == "" {
+			continue
+		}
+		if _, ok := ids[template.ReplacedBy]; !ok {
+			unknown = append(unknown, template)
+		}
+	}
+	return unknown
+}
+
+// checkEnvVariables returns true if the environment variables used by
+// a template are allowed and set, warning about the ones which are not.
+func (r *Runner) checkEnvVariables(template *templates.Template) bool {
+	usable := true
+	for _, request := range template.RequestsHTTP {
+		for _, name := range request.GetEnvVariables() {
+			if _, ok := r.envVars[name]; !ok {
+				gologger.Labelf("[%s] Environment variable %s is not allowed, allow it with -env-vars\n", template.ID, name)
+				usable = false
+				continue
+			}
+			if _, ok := os.LookupEnv(name); !ok {
+				gologger.Labelf("[%s] Environment variable %s is not set\n", template.ID, name)
+				usable = false
+			}
+		}
+	}
+	return usable
+}
+
+// makeDialerOptions returns the options of the dialer connecting to the hosts
+func (r *Runner) makeDialerOptions() *executor.DialerOptions {
+	return &executor.DialerOptions{
+		ConnectTimeout:  r.options.ConnectTimeout,
+		KeepAlive:       r.options.KeepAlive,
+		NoHappyEyeballs: r.options.NoHappyEyeballs,
+	}
+}
+
+// makeRequestBudget creates the request budget of a template, using the
+// lowest of the template and global limits. It returns nil if unlimited.
+func (r *Runner) makeRequestBudget(template *templates.Template) *executor.RequestBudget {
+	max := template.MaxRequests
+	if r.options.RequestBudget > 0 && (max <= 0 || r.options.RequestBudget < max) {
+		max = r.options.RequestBudget
+	}
+	if max <= 0 {
+		return nil
+	}
+	return r.newRequestBudget(max)
+}
+
+// newRequestBudget creates a request budget allowing max requests per
+// host, only keeping the counts of the recent hosts in bounded mode.
+func (r *Runner) newRequestBudget(max int) *executor.RequestBudget {
+	if r.options.Bounded {
+		return executor.NewBoundedRequestBudget(max, boundedMaxBudgetHosts)
+	}
+	return executor.NewRequestBudget(max)
+}
+
+// publishProgress publishes a progress event to the stream and
+// the health server if any
+func (r *Runner) publishProgress(event *progressEvent) {
+	r.health.setProgress(event)
+	if r.stream != nil {
+		r.stream.publish("progress", event)
+	}
+}
+
+// onHostBlock reports a host blocking the requests of the scan
+func (r *Runner) onHostBlock(host string, stats *executor.HostStats) {
+	switch {
+	case stats.Paused:
+		gologger.Warningf("Host %s keeps blocking requests, pausing it\n", host)
+	case stats.Blocks == 1:
+		gologger.Warningf("Host %s is blocking requests, slowing down to %s between requests\n", host, stats.Delay)
+	default:
+		gologger.Verbosef("Host blocked request, slowing down to %s between requests\n", host, stats.Delay)
+	}
+	if r.stream != nil {
+		r.stream.publish("throttle", stats)
+	}
+}
+
+// makeOutputWriter creates the writer of the results to the screen,
+// the output file and the event stream as asked.
+func (r *Runner) makeOutputWriter() *executor.OutputWriter {
+	options := &executor.OutputOptions{Sinks: []io.Writer{os.Stdout}, Spill: r.options.Bounded}
+	if r.output != nil {
+		options.Sinks = append(options.Sinks, r.output)
+	}
+	if r.stream != nil {
+		options.Callbacks = append(options.Callbacks, func(result *executor.Result) {
+			r.stream.publish("result", result)
+		})
+	}
+	return executor.NewOutputWriter(options)
+}
+
+// isSingleTemplate returns true if the template path is a single
+// template file. Its http requests run before its dns requests,
+// unlike the templates of globs and directories.
+func (r *Runner) isSingleTemplate() bool {
+	return !strings.Contains(r.options.Templates, "*") && strings.HasSuffix(r.options.Templates, ".yaml")
+}
+
+// getTemplatePaths returns the template files the user asked to run
+func (r *Runner) getTemplatePaths() []string {
+	// Only rule packs may be run
+	if r.options.Templates == "" {
+		return nil
+	}
+	// If the template path is a single template and not a glob, use that.
+	if r.isSingleTemplate() {
+		return []string{r.options.Templates}
+	}
 	// If the template path is glob
 	if strings.Contains(r.options.Templates, "*") {
 		// Handle the glob, evaluate it and run all the template file checks
@@ -94,28 +555,14 @@ func (r *Runner) RunEnumeration() {
 		if err != nil {
 			gologger.Fatalf("Could not evaluate template path '%s': %s\n", r.options.Templates, err)
 		}
-
-		for _, match := range matches {
-			template, err := templates.ParseTemplate(match)
-			if err != nil {
-				gologger.Errorf("Could not parse template file '%s': %s\n", match, err)
-				return
-			}
-			for _, request := range template.RequestsDNS {
-				r.processTemplateRequest(template, request)
-			}
-			for _, request := range template.RequestsHTTP {
-				r.processTemplateRequest(template, request)
-			}
-		}
-		return
+		return filterFixtures(matches)
 	}
 	// If the template passed is a directory
 	matches := []string{}
 	// Recursively walk down the Templates directory and run all the template file checks
 	err := godirwalk.Walk(r.options.Templates, &godirwalk.Options{
 		Callback: func(path string, d *godirwalk.Dirent) error {
-			if !d.IsDir() && strings.HasSuffix(path, ".yaml") {
+			if !d.IsDir() && strings.HasSuffix(path, ".yaml") && !templates.IsFixture(path) {
 				matches = append(matches, path)
 			}
 			return nil
@@ -132,42 +579,43 @@ func (r *Runner) RunEnumeration() {
 	if len(matches) == 0 {
 		gologger.Fatalf("Error, no templates found in directory: '%s'\n", r.options.Templates)
 	}
-	for _, match := range matches {
-		template, err := templates.ParseTemplate(match)
-		if err != nil {
-			gologger.Errorf("Could not parse template file '%s': %s\n", match, err)
-			return
-		}
-		for _, request := range template.RequestsDNS {
-			r.processTemplateRequest(template, request)
-		}
-		for _, request := range template.RequestsHTTP {
-			r.processTemplateRequest(template, request)
+	return matches
+}
+
+// filterFixtures removes the fixture files from a list of paths
+func filterFixtures(paths []string) []string {
+	filtered := paths[:0]
+	for _, path := range paths {
+		if !templates.IsFixture(path) {
+			filtered = append(filtered, path)
 		}
 	}
-	return
+	return filtered
 }
 
 // processTemplate processes a template and runs the enumeration on all the targets
-func (r *Runner) processTemplateRequest(template *templates.Template, request interface{}) {
-	var file *os.File
-	var err error
-
-	// Handle a list of hosts as argument
-	if r.options.Targets != "" {
-		file, err = os.Open(r.options.Targets)
-	} else if r.options.Stdin {
-		file, err = os.Open(r.tempFile)
-	}
+func (r *Runner) processTemplateRequest(template *templates.Template, request interface{}, budget *executor.RequestBudget) {
+	file, err := os.Open(r.inputFile)
 	if err != nil {
-		gologger.Fatalf("Could not open targets file '%s': %s\n", r.options.Targets, err)
+		gologger.Fatalf("Could not open targets file '%s': %s\n", r.inputFile, err)
+	}
+	defer file.Close()
+
+	// Only the requests asking for them are sent to the discovered urls
+	var reader io.Reader = file
+	if request, ok := request.(*requests.HTTPRequest); ok && request.Discovered && r.discoveredFile != "" {
+		discovered, err := os.Open(r.discoveredFile)
+		if err != nil {
+			gologger.Fatalf("Could not open discovered urls file '%s': %s\n", r.discoveredFile, err)
+		}
+		defer discovered.Close()
+		reader = io.MultiReader(file, strings.NewReader("\n"), discovered)
 	}
-	r.processTemplateWithList(template, request, file)
-	file.Close()
+	r.processTemplateWithList(template, request, budget, reader)
 }
 
 // processDomain processes the list with a template
-func (r *Runner) processTemplateWithList(template *templates.Template, request interface{}, reader io.Reader) {
+func (r *Runner) processTemplateWithList(template *templates.Template, request interface{}, budget *executor.RequestBudget, reader io.Reader) {
 	// Display the message for the template
 	message := fmt.Sprintf("[%s] Loaded template %s (@%s)", template.ID, template.Info.Name, template.Info.Author)
 	if template.Info.Severity != "" {
@@ -175,12 +623,6 @@ func (r *Runner) processTemplateWithList(template *templates.Template, request i
 	}
 	gologger.Infof("%s\n", message)
 
-	var writer *bufio.Writer
-	if r.output != nil {
-		writer = bufio.NewWriter(r.output)
-		defer writer.Flush()
-	}
-
 	var httpExecutor *executor.HTTPExecutor
 	var dnsExecutor *executor.DNSExecutor
 	var err error
@@ -191,29 +633,59 @@ func (r *Runner) processTemplateWithList(template *templates.Template, request i
 		dnsExecutor = executor.NewDNSExecutor(&executor.DNSOptions{
 			Template:   template,
 			DNSRequest: value,
-			Writer:     writer,
+			Output:     r.outputWriter,
+			Extracts:   r.extractWriter,
+			JSON:       r.options.JSON,
+			Budget:     budget,
+			Resolver:   r.resolver,
 		})
 	case *requests.HTTPRequest:
-		httpExecutor, err = executor.NewHTTPExecutor(&executor.HTTPOptions{
-			Template:      template,
-			HTTPRequest:   value,
-			Writer:        writer,
-			Timeout:       r.options.Timeout,
-			Retries:       r.options.Retries,
-			ProxyURL:      r.options.ProxyURL,
-			ProxySocksURL: r.options.ProxySocksURL,
-		})
+		httpOptions := &executor.HTTPOptions{
+			Template:       template,
+			HTTPRequest:    value,
+			Output:         r.outputWriter,
+			Extracts:       r.extractWriter,
+			Timeout:        r.options.Timeout,
+			Retries:        r.options.Retries,
+			ProxyURL:       r.options.ProxyURL,
+			ProxySocksURL:  r.options.ProxySocksURL,
+			ProxyAuth:      r.options.ProxyAuth,
+			Dialer:         r.makeDialerOptions(),
+			JSON:           r.options.JSON,
+			Budget:         budget,
+			Throttle:       r.throttle,
+			BlindXSS:       r.blindXSS,
+			CorrelationLog: r.correlationLog,
+			EnvValues:      r.envValues,
+			Spoof:          r.spoof,
+			Resolver:       r.resolver,
+		}
+		// Only read the start of huge responses in bounded mode
+		if r.options.Bounded {
+			httpOptions.MaxBodySize = boundedMaxBodySize
+		}
+		httpExecutor, err = executor.NewHTTPExecutor(httpOptions)
 	}
 	if err != nil {
 		gologger.Warningf("Could not create http client: %s\n", err)
 		return
 	}
 
-	limiter := make(chan struct{}, r.options.Threads)
+	// The limiter allows the maximum concurrency when adapting it,
+	// the controller limiting the targets processed as they start.
+	threads := r.options.Threads
+	if r.concurrency != nil {
+		threads = r.options.AdaptiveMax
+	}
+	limiter := make(chan struct{}, threads)
 	wg := &sync.WaitGroup{}
 
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
+		// Stop starting new targets once the scan was asked to stop
+		if r.isStopping() {
+			break
+		}
 		text := scanner.Text()
 		if text == "" {
 			continue
@@ -224,12 +696,15 @@ func (r *Runner) processTemplateWithList(template *templates.Template, request i
 		go func(URL string) {
 			var err error
 
+			host := getTargetHost(URL)
+			r.concurrency.Acquire(host)
 			if httpExecutor != nil {
-				err = httpExecutor.ExecuteHTTP(text)
+				err = httpExecutor.ExecuteHTTP(URL)
 			}
 			if dnsExecutor != nil {
-				err = dnsExecutor.ExecuteDNS(text)
+				err = dnsExecutor.ExecuteDNS(URL)
 			}
+			r.concurrency.Release(host, err)
 			if err != nil {
 				gologger.Warningf("Could not execute step: %s\n", err)
 			}
@@ -240,3 +715,21 @@ func (r *Runner) processTemplateWithList(template *templates.Template, request i
 	close(limiter)
 	wg.Wait()
 }
+
+// openOutputFile opens the output file, appending to it when resuming
+// a scan instead of truncating the results written before.
+func openOutputFile(path string, resume bool) (*os.File, error) {
+	if resume {
+		return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	}
+	return os.Create(path)
+}
+
+// getTargetHost returns the host of a target url, or the target
+// itself for domains.
+func getTargetHost(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return target
+}
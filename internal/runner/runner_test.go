@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/pkg/executor"
+	"github.com/projectdiscovery/nuclei/pkg/requests"
+	"github.com/projectdiscovery/nuclei/pkg/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUnknownReplacements(t *testing.T) {
+	deprecated := []*templates.Template{
+		{ID: "old-known", ReplacedBy: "new"},
+		{ID: "old-unknown", ReplacedBy: "missing"},
+		{ID: "old-unreplaced"},
+	}
+	unknown := getUnknownReplacements(deprecated, map[string]struct{}{"new": {}})
+	require.Len(t, unknown, 1, "Could not find unknown replacements")
+	require.Equal(t, "old-unknown", unknown[0].ID, "Could not get template with unknown replacement")
+}
+
+// writeTestTemplate writes a template requesting the base url of the targets
+func writeTestTemplate(t *testing.T, dir, id, extra string) string {
+	path := filepath.Join(dir, id+".yaml")
+	data := fmt.Sprintf(`id: %s
+info:
+  name: %s
+  author: test
+%srequests:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: status
+        status:
+          - 200
+`, id, id, extra)
+	require.Nil(t, ioutil.WriteFile(path, []byte(data), 0644), "Could not write template")
+	return path
+}
+
+func TestParseTemplatesDeprecated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-templates-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	paths := []string{
+		writeTestTemplate(t, dir, "old", "deprecated: true\nreplaced-by: new\n"),
+		writeTestTemplate(t, dir, "new", ""),
+	}
+	state, err := loadScanState(filepath.Join(dir, "scan.state"))
+	require.Nil(t, err, "Could not load state")
+	r := &Runner{options: &Options{SkipDeprecated: true}, state: state}
+
+	// The deprecated templates are still reported when skipped
+	var parsed []*templates.Template
+	r.parseTemplates(paths, func(template *templates.Template, path string) {
+		parsed = append(parsed, template)
+	})
+	require.Len(t, parsed, 2, "Could not report skipped deprecated template")
+	require.True(t, parsed[0].Deprecated, "Could not parse deprecated template")
+	require.Equal(t, "new", parsed[0].GetMetadata(paths[0]).ReplacedBy, "Could not report replacement in metadata")
+
+	r.runTemplate(parsed[0])
+	require.False(t, state.isCompleted("old"), "Could not skip deprecated template")
+}
+
+func TestRunTemplateEnvVariables(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "nuclei-templates-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "targets.txt")
+	require.Nil(t, ioutil.WriteFile(input, []byte(server.URL), 0644), "Could not write targets")
+	path := filepath.Join(dir, "env.yaml")
+	require.Nil(t, ioutil.WriteFile(path, []byte(`id: env
+info:
+  name: env
+  author: test
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+    headers:
+      Authorization: 'Bearer {{env "NUCLEI_TEST_KEY"}}'
+`), 0644), "Could not write template")
+	template, err := templates.ParseTemplate(path)
+	require.Nil(t, err, "Could not parse template")
+
+	os.Setenv("NUCLEI_TEST_KEY", "secret")
+	defer os.Unsetenv("NUCLEI_TEST_KEY")
+
+	output := executor.NewOutputWriter(&executor.OutputOptions{})
+	defer output.Close()
+	r := &Runner{inputFile: input, outputWriter: output, options: &Options{Timeout: 5, Threads: 1}}
+	r.runTemplate(template)
+	require.Empty(t, authorizations, "Could run template using variable which is not allowed")
+
+	r.envVars = map[string]struct{}{"NUCLEI_TEST_KEY": {}}
+	r.envValues = requests.EnvValues([]string{"NUCLEI_TEST_KEY"})
+	r.runTemplate(template)
+	require.Equal(t, []string{"Bearer secret"}, authorizations, "Could not run template using allowed variable")
+
+	os.Unsetenv("NUCLEI_TEST_KEY")
+	authorizations = nil
+	r.runTemplate(template)
+	require.Empty(t, authorizations, "Could run template using variable which is not set")
+}
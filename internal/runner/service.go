@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// notifyService sends a state notification to the service manager, such
+// as READY=1 or STOPPING=1, when running as a systemd notify service.
+func notifyService(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+	// Abstract sockets are given with a leading @
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		gologger.Warningf("Could not notify service manager: %s\n", err)
+		return
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		gologger.Warningf("Could not notify service manager: %s\n", err)
+	}
+}
+
+// startWatchdog pings the watchdog of the service manager at half of
+// its interval, if the service manager asked for it.
+func startWatchdog() {
+	usec, err := strconv.Atoi(os.Getenv("WATCHDOG_USEC"))
+	if err != nil || usec <= 0 {
+		return
+	}
+	// The watchdog is only meant for the process it was set up for
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(usec) * time.Microsecond / 2)
+	go func() {
+		for range ticker.C {
+			notifyService("WATCHDOG=1")
+		}
+	}()
+}
+
+// handleSignals stops the scan gracefully on the first interrupt or
+// termination signal, letting the running requests finish and the
+// results and state be written, and exits on the second one.
+func (r *Runner) handleSignals() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-signals
+		gologger.Labelf("Stopping the scan, interrupt again to exit immediately\n")
+		notifyService("STOPPING=1")
+		atomic.StoreInt32(&r.stopping, 1)
+
+		<-signals
+		os.Exit(1)
+	}()
+}
+
+// isStopping returns true if the scan was asked to stop
+func (r *Runner) isStopping() bool {
+	return atomic.LoadInt32(&r.stopping) == 1
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package runner
+
+// windowsService is only used when running as a windows service
+type windowsService struct{}
+
+// startService does nothing as windows services are only supported on windows.
+func (r *Runner) startService() {}
+
+// stopService does nothing as windows services are only supported on windows.
+func (r *Runner) stopService() {}
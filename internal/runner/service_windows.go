@@ -0,0 +1,76 @@
+//go:build windows
+// +build windows
+
+package runner
+
+import (
+	"sync/atomic"
+
+	"github.com/projectdiscovery/gologger"
+	"golang.org/x/sys/windows/svc"
+)
+
+// serviceName is the name nuclei runs under as a windows service
+const serviceName = "nuclei"
+
+// windowsService reports the state of the scan to the windows service
+// control manager and stops the scan gracefully when asked.
+type windowsService struct {
+	runner *Runner
+	// done is closed once the scan finished
+	done chan struct{}
+	// exited is closed once the service control manager released the service
+	exited chan struct{}
+}
+
+// startService runs the scan as a windows service when started by
+// the service control manager.
+func (r *Runner) startService() {
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil || interactive {
+		return
+	}
+
+	service := &windowsService{runner: r, done: make(chan struct{}), exited: make(chan struct{})}
+	r.service = service
+	go func() {
+		if err := svc.Run(serviceName, service); err != nil {
+			gologger.Warningf("Could not run as windows service: %s\n", err)
+		}
+		close(service.exited)
+	}()
+}
+
+// stopService reports the service as stopped once the scan finished.
+func (r *Runner) stopService() {
+	if r.service == nil {
+		return
+	}
+	close(r.service.done)
+	<-r.service.exited
+}
+
+// Execute handles the requests of the service control manager until
+// the scan finishes.
+func (s *windowsService) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case <-s.done:
+			status <- svc.Status{State: svc.StopPending}
+			return false, 0
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				// Let the running requests finish like on an interrupt
+				gologger.Labelf("Stopping the scan\n")
+				status <- svc.Status{State: svc.StopPending}
+				atomic.StoreInt32(&s.runner.stopping, 1)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// scanState is the state of a scan persisted to resume it after a crash
+// or a restart, skipping the templates already run on all the targets.
+type scanState struct {
+	mutex *sync.Mutex
+	path  string
+	// Completed contains the ids of the templates run on all the targets
+	Completed []string `json:"completed"`
+	completed map[string]struct{}
+}
+
+// loadScanState loads the state of a scan from a file, starting
+// from an empty state if the file doesn't exist.
+func loadScanState(path string) (*scanState, error) {
+	state := &scanState{
+		mutex:     &sync.Mutex{},
+		path:      path,
+		completed: make(map[string]struct{}),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	for _, id := range state.Completed {
+		state.completed[id] = struct{}{}
+	}
+	return state, nil
+}
+
+// isResumed returns true if the state is resumed from a previous
+// scan which completed templates. A nil state is never resumed.
+func (s *scanState) isResumed() bool {
+	if s == nil {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.Completed) > 0
+}
+
+// isCompleted returns true if a template was already run on all the
+// targets. A nil state has no completed templates.
+func (s *scanState) isCompleted(id string) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, ok := s.completed[id]
+	return ok
+}
+
+// complete records a template as run on all the targets and saves the state.
+func (s *scanState) complete(id string) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.completed[id]; ok {
+		return nil
+	}
+	s.completed[id] = struct{}{}
+	s.Completed = append(s.Completed, id)
+	return s.save()
+}
+
+// save writes the state to a temporary file replacing the state file,
+// so that a crash while saving never leaves a partially written state.
+func (s *scanState) save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), s.path)
+}
+
+// remove removes the state file once the scan is finished.
+func (s *scanState) remove() {
+	if s == nil {
+		return
+	}
+	os.Remove(s.path)
+}
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanStateResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-state-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "scan.state")
+	state, err := loadScanState(path)
+	require.Nil(t, err, "Could not load missing state")
+	require.False(t, state.isResumed(), "Missing state was resumed")
+
+	require.Nil(t, state.complete("first"), "Could not complete template")
+	require.Nil(t, state.complete("first"), "Could not complete template twice")
+	require.Nil(t, state.complete("second"), "Could not complete template")
+
+	resumed, err := loadScanState(path)
+	require.Nil(t, err, "Could not load saved state")
+	require.True(t, resumed.isResumed(), "Saved state was not resumed")
+	require.Equal(t, []string{"first", "second"}, resumed.Completed, "Could not get completed templates")
+	require.True(t, resumed.isCompleted("first"), "Completed template was not skipped")
+	require.False(t, resumed.isCompleted("third"), "Pending template was skipped")
+
+	resumed.remove()
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err), "Could not remove state file")
+
+	var empty *scanState
+	require.False(t, empty.isResumed(), "Nil state was resumed")
+	require.False(t, empty.isCompleted("first"), "Nil state has completed templates")
+}
+
+func TestOpenOutputFileResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-output-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "output.txt")
+	require.Nil(t, ioutil.WriteFile(path, []byte("previous\n"), 0644), "Could not write output file")
+
+	output, err := openOutputFile(path, true)
+	require.Nil(t, err, "Could not open output file")
+	output.WriteString("resumed\n")
+	output.Close()
+
+	data, err := ioutil.ReadFile(path)
+	require.Nil(t, err, "Could not read output file")
+	require.Equal(t, "previous\nresumed\n", string(data), "Could not append to output file when resuming")
+
+	output, err = openOutputFile(path, false)
+	require.Nil(t, err, "Could not create output file")
+	output.WriteString("new\n")
+	output.Close()
+
+	data, err = ioutil.ReadFile(path)
+	require.Nil(t, err, "Could not read output file")
+	require.Equal(t, "new\n", string(data), "Could not truncate output file for a new scan")
+}
@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// eventStream streams the scan events to http clients using
+// server-sent events.
+type eventStream struct {
+	mutex   *sync.Mutex
+	clients map[chan []byte]struct{}
+	server  *http.Server
+}
+
+// streamEvent is an event sent to the clients of the stream
+type streamEvent struct {
+	// Type is the type of the event, result or progress
+	Type string `json:"type"`
+	// Data contains the payload of the event
+	Data interface{} `json:"data"`
+}
+
+// progressEvent is a progress event of the scan
+type progressEvent struct {
+	// Status is the state of the scan, started, template or finished
+	Status string `json:"status"`
+	// Template is the id of the template being run, if any
+	Template string `json:"template,omitempty"`
+}
+
+// clientBufferSize is the number of events buffered for a slow client
+// before events are dropped for it.
+const clientBufferSize = 256
+
+// serverShutdownTimeout is the time given to the clients of the
+// servers to receive the last events before they are disconnected.
+const serverShutdownTimeout = 5 * time.Second
+
+// newEventStream starts listening for stream clients on an address
+func newEventStream(address string) (*eventStream, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := &eventStream{
+		mutex:   &sync.Mutex{},
+		clients: make(map[chan []byte]struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/events", stream)
+	stream.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := stream.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			gologger.Warningf("Could not serve event stream: %s\n", err)
+		}
+	}()
+	return stream, nil
+}
+
+// ServeHTTP streams the events to a client until it disconnects
+func (s *eventStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	events := make(chan []byte, clientBufferSize)
+	s.mutex.Lock()
+	s.clients[events] = struct{}{}
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.clients, events)
+		s.mutex.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			w.Write(event)
+			flusher.Flush()
+		}
+	}
+}
+
+// publish sends an event to all the connected clients
+func (s *eventStream) publish(eventType string, data interface{}) {
+	payload, err := json.Marshal(&streamEvent{Type: eventType, Data: data})
+	if err != nil {
+		return
+	}
+	message := []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", eventType, payload))
+
+	s.mutex.Lock()
+	for client := range s.clients {
+		// Drop the event for clients that can't keep up
+		select {
+		case client <- message:
+		default:
+		}
+	}
+	s.mutex.Unlock()
+}
+
+// Close disconnects all the clients once they received the pending
+// events and stops the server.
+func (s *eventStream) Close() {
+	s.mutex.Lock()
+	for client := range s.clients {
+		close(client)
+		delete(s.clients, client)
+	}
+	s.mutex.Unlock()
+	shutdownServer(s.server)
+}
+
+// shutdownServer gracefully shuts down a server, closing it if the
+// clients are not done before the timeout.
+func shutdownServer(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		server.Close()
+	}
+}
@@ -0,0 +1,146 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/pkg/executor"
+	"github.com/projectdiscovery/nuclei/pkg/templates"
+)
+
+// runTests verifies the templates having a fixture file by running
+// them against a local server serving the responses of the fixture.
+//
+// It returns the number of templates which failed their test.
+func (r *Runner) runTests(paths []string) int {
+	var passed, failed int
+
+	for _, path := range paths {
+		fixturePath := templates.GetFixturePath(path)
+		if _, err := os.Stat(fixturePath); err != nil {
+			gologger.Verbosef("Skipping template without fixture %s\n", "test", path)
+			continue
+		}
+
+		template, err := templates.ParseTemplate(path)
+		if err != nil {
+			gologger.Errorf("Could not parse template file '%s': %s\n", path, err)
+			failed++
+			continue
+		}
+		fixture, err := templates.LoadFixture(fixturePath)
+		if err != nil {
+			gologger.Errorf("Could not load fixture file '%s': %s\n", fixturePath, err)
+			failed++
+			continue
+		}
+
+		if err := r.testTemplate(template, fixture); err != nil {
+			gologger.Errorf("[%s] Test failed: %s\n", template.ID, err)
+			failed++
+			continue
+		}
+		gologger.Infof("[%s] Test passed\n", template.ID)
+		passed++
+	}
+
+	gologger.Infof("%d template tests passed, %d failed\n", passed, failed)
+	return failed
+}
+
+// testTemplate runs the http requests of a template against the fixture
+// and compares the results with the expected ones.
+//
+// Templates with dns requests fail as fixtures only serve http responses.
+func (r *Runner) testTemplate(template *templates.Template, fixture *templates.Fixture) error {
+	if len(template.RequestsDNS) > 0 {
+		return fmt.Errorf("dns requests can't be tested against a fixture")
+	}
+
+	server := httptest.NewServer(fixtureHandler(fixture))
+	defer server.Close()
+
+	var results []*executor.Result
+	output := executor.NewOutputWriter(&executor.OutputOptions{
+		Sinks: []io.Writer{os.Stdout},
+		Callbacks: []executor.ResultCallback{func(result *executor.Result) {
+			results = append(results, result)
+		}},
+	})
+
+	err := r.runTestRequests(template, server.URL, output)
+	output.Close()
+	if err != nil {
+		return err
+	}
+
+	if matched := len(results) > 0; matched != fixture.Matched {
+		return fmt.Errorf("expected matched to be %t, got %t", fixture.Matched, matched)
+	}
+
+	extracted := make(map[string]struct{})
+	for _, result := range results {
+		for _, value := range result.ExtractedResults {
+			extracted[value] = struct{}{}
+		}
+	}
+	for _, value := range fixture.Extracted {
+		if _, ok := extracted[value]; !ok {
+			return fmt.Errorf("expected value %s was not extracted", value)
+		}
+	}
+	return nil
+}
+
+// runTestRequests runs the http requests of a template on a target
+func (r *Runner) runTestRequests(template *templates.Template, target string, output *executor.OutputWriter) error {
+	for _, request := range template.RequestsHTTP {
+		httpExecutor, err := executor.NewHTTPExecutor(&executor.HTTPOptions{
+			Template:    template,
+			HTTPRequest: request,
+			Output:      output,
+			Timeout:     r.options.Timeout,
+			JSON:        r.options.JSON,
+			EnvValues:   r.envValues,
+			Resolver:    r.resolver,
+		})
+		if err != nil {
+			return err
+		}
+		if err := httpExecutor.ExecuteHTTP(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fixtureHandler serves the responses of a fixture, returning
+// a not found error for the requests not matching any of them.
+func fixtureHandler(fixture *templates.Fixture) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, response := range fixture.Responses {
+			if response.Path != r.URL.RequestURI() && response.Path != r.URL.Path {
+				continue
+			}
+			if response.Method != "" && response.Method != r.Method {
+				continue
+			}
+
+			for key, value := range response.Headers {
+				w.Header().Set(key, value)
+			}
+			status := response.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(response.Body))
+			return
+		}
+		http.NotFound(w, r)
+	})
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/pkg/requests"
+	"github.com/projectdiscovery/nuclei/pkg/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixtureHandler(t *testing.T) {
+	server := httptest.NewServer(fixtureHandler(&templates.Fixture{Responses: []*templates.FixtureResponse{
+		{Path: "/login", Method: http.MethodPost, Status: http.StatusFound, Headers: map[string]string{"Location": "/home"}},
+		{Path: "/search?q=1", Body: "query"},
+		{Path: "/", Body: "index"},
+	}}))
+	defer server.Close()
+
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	get := func(method, path string) (*http.Response, string) {
+		req, err := http.NewRequest(method, server.URL+path, nil)
+		require.Nil(t, err, "Could not create request")
+		resp, err := client.Do(req)
+		require.Nil(t, err, "Could not send request")
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp, string(body)
+	}
+
+	resp, body := get(http.MethodGet, "/")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "Could not default status to 200")
+	require.Equal(t, "index", body, "Could not serve body")
+
+	_, body = get(http.MethodGet, "/search?q=1")
+	require.Equal(t, "query", body, "Could not match path with query")
+
+	resp, _ = get(http.MethodPost, "/login")
+	require.Equal(t, http.StatusFound, resp.StatusCode, "Could not serve status")
+	require.Equal(t, "/home", resp.Header.Get("Location"), "Could not serve headers")
+
+	resp, _ = get(http.MethodGet, "/login")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "Could not restrict response to method")
+
+	resp, _ = get(http.MethodGet, "/missing")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "Could not serve not found for unknown paths")
+}
+
+func TestTestTemplateDNS(t *testing.T) {
+	r := &Runner{options: &Options{Timeout: 5}}
+	template := &templates.Template{ID: "dns", RequestsDNS: []*requests.DNSRequest{{Name: "{{FQDN}}"}}}
+	err := r.testTemplate(template, &templates.Fixture{})
+	require.NotNil(t, err, "Could not fail template with dns requests")
+}
+
+func TestTestTemplateRulePack(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-test-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "secrets.json")
+	require.Nil(t, ioutil.WriteFile(file, []byte(`{"Slack Token": "xox[baprs]-[0-9]+", "AWS Key": "AKIA[0-9A-Z]{16}"}`), 0644), "Could not write file")
+	template, _, err := templates.ParseRulePack(file)
+	require.Nil(t, err, "Could not parse rule pack")
+
+	r := &Runner{options: &Options{Timeout: 5}}
+	clean := &templates.Fixture{Responses: []*templates.FixtureResponse{{Path: "/", Body: "nothing to see"}}}
+	require.Nil(t, r.testTemplate(template, clean), "Could not skip result for clean response")
+
+	leaked := &templates.Fixture{
+		Matched:   true,
+		Extracted: []string{"xoxb-1234"},
+		Responses: []*templates.FixtureResponse{{Path: "/", Body: "token = xoxb-1234"}},
+	}
+	require.Nil(t, r.testTemplate(template, leaked), "Could not report leaked secret")
+}
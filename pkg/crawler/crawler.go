@@ -0,0 +1,191 @@
+package crawler
+
+import (
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Options contains configuration options for the crawler.
+type Options struct {
+	// Depth is the maximum number of links followed from the target.
+	Depth int
+	// MaxURLs is the maximum number of urls discovered per target.
+	MaxURLs int
+	// Timeout is the seconds to wait for a response from the server.
+	Timeout int
+	// MaxBodySize is the maximum number of bytes read from each page.
+	MaxBodySize int64
+	// Transport optionally sends the requests of the crawler, a
+	// transport skipping the tls verification being used by default.
+	Transport http.RoundTripper
+}
+
+// DefaultOptions contains the default options for the crawler.
+var DefaultOptions = Options{
+	Depth:       2,
+	MaxURLs:     100,
+	Timeout:     5,
+	MaxBodySize: 2 * 1024 * 1024,
+}
+
+// Crawler discovers urls from targets, staying on the host of the target.
+type Crawler struct {
+	options *Options
+}
+
+// New creates a new crawler.
+func New(options *Options) *Crawler {
+	return &Crawler{options: options}
+}
+
+// linkAttributes contains the attributes holding links for each tag
+var linkAttributes = map[string]string{
+	"a":      "href",
+	"link":   "href",
+	"area":   "href",
+	"form":   "action",
+	"iframe": "src",
+	"frame":  "src",
+	"script": "src",
+}
+
+// Crawl crawls a target and returns the urls discovered, including the target.
+//
+// Cookies set by the target are kept for the whole crawl so that pages
+// requiring the session created by earlier pages are reachable.
+func (c *Crawler) Crawl(target string) ([]string, error) {
+	start, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.makeClient(start)
+	if err != nil {
+		return nil, err
+	}
+	defer client.CloseIdleConnections()
+
+	seen := map[string]struct{}{start.String(): {}}
+	results := []string{start.String()}
+	queue := []*url.URL{start}
+
+	for depth := 0; depth < c.options.Depth && len(queue) > 0; depth++ {
+		var next []*url.URL
+		for _, page := range queue {
+			links, err := c.getLinks(client, page)
+			if err != nil {
+				continue
+			}
+			for _, link := range links {
+				if link.Host != start.Host || link.Scheme != start.Scheme {
+					continue
+				}
+				if _, ok := seen[link.String()]; ok {
+					continue
+				}
+				if len(results) >= c.options.MaxURLs {
+					return results, nil
+				}
+				seen[link.String()] = struct{}{}
+				results = append(results, link.String())
+				next = append(next, link)
+			}
+		}
+		queue = next
+	}
+	return results, nil
+}
+
+// makeClient creates a http client keeping the cookies of a session
+// and only following redirects on the host of the target.
+func (c *Crawler) makeClient(target *url.URL) (*http.Client, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	transport := c.options.Transport
+	if transport == nil {
+		transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+	return &http.Client{
+		Jar:       jar,
+		Timeout:   time.Duration(c.options.Timeout) * time.Second,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// Don't follow redirects leaving the target
+			if req.URL.Host != target.Host || len(via) > 10 {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}, nil
+}
+
+// getLinks returns the links found on a page
+func (c *Crawler) getLinks(client *http.Client, page *url.URL) ([]*url.URL, error) {
+	req, err := http.NewRequest(http.MethodGet, page.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Nuclei (@pdiscoveryio)")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return nil, nil
+	}
+	return ParseLinks(resp.Request.URL, io.LimitReader(resp.Body, c.options.MaxBodySize)), nil
+}
+
+// ParseLinks parses the links of a html page, resolved from the page url.
+//
+// Fragments are removed from the links and links which are not http
+// or https are skipped.
+func ParseLinks(page *url.URL, body io.Reader) []*url.URL {
+	var links []*url.URL
+
+	tokenizer := html.NewTokenizer(body)
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return links
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		attribute, ok := linkAttributes[token.Data]
+		if !ok {
+			continue
+		}
+		for _, attr := range token.Attr {
+			if attr.Key != attribute || attr.Val == "" {
+				continue
+			}
+			link, err := page.Parse(strings.TrimSpace(attr.Val))
+			if err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+				continue
+			}
+			link.Fragment = ""
+			links = append(links, link)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLinks(t *testing.T) {
+	page, _ := url.Parse("https://example.com/dir/page")
+	links := ParseLinks(page, strings.NewReader(`<html><body>
+<a href="/about#team">about</a>
+<a href="next">next</a>
+<a href="mailto:admin@example.com">mail</a>
+<form action="https://other.com/login"></form>
+<img src="/image.png">
+<script src="//cdn.example.com/app.js"></script>
+</body></html>`))
+
+	var values []string
+	for _, link := range links {
+		values = append(values, link.String())
+	}
+	require.Equal(t, []string{
+		"https://example.com/about",
+		"https://example.com/dir/next",
+		"https://other.com/login",
+		"https://cdn.example.com/app.js",
+	}, values, "Could not parse links")
+}
+
+// newTestSite starts a site whose private page is only linked
+// once the session cookie set by the home page is sent.
+func newTestSite() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "1"})
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/a">a</a><a href="/a#top">a</a><a href="http://other.example.com/">other</a>`)
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if _, err := r.Cookie("session"); err == nil {
+			fmt.Fprint(w, `<a href="/private">private</a>`)
+		}
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/deep">deep</a>`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCrawl(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	options := DefaultOptions
+	results, err := New(&options).Crawl(server.URL)
+	require.Nil(t, err, "Could not crawl target")
+	require.Equal(t, []string{server.URL, server.URL + "/a", server.URL + "/private"}, results, "Could not crawl links of the target up to the depth")
+
+	options.MaxURLs = 2
+	results, err = New(&options).Crawl(server.URL)
+	require.Nil(t, err, "Could not crawl target")
+	require.Len(t, results, 2, "Could not limit the urls discovered")
+}
+
+// countingTransport counts the requests sent through a transport
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestCrawlTransport(t *testing.T) {
+	server := newTestSite()
+	defer server.Close()
+
+	transport := &countingTransport{}
+	options := DefaultOptions
+	options.Transport = transport
+	_, err := New(&options).Crawl(server.URL)
+	require.Nil(t, err, "Could not crawl target")
+	require.Equal(t, int32(2), atomic.LoadInt32(&transport.requests), "Could not send the requests through the transport")
+}
@@ -0,0 +1,3 @@
+// Package crawler implements a lightweight crawler discovering
+// urls from targets to use as additional scan input.
+package crawler
@@ -0,0 +1,125 @@
+package crawler
+
+import (
+	"io"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// endpointRegex matches the quoted absolute urls, absolute paths and
+// relative paths to files in javascript code.
+var endpointRegex = regexp.MustCompile("[\"'`](" +
+	"(?:https?:)?//[A-Za-z0-9.\\-]+(?::\\d+)?(?:/[^\"'`\\s<>]*)?|" +
+	"/[A-Za-z0-9_\\-.~%]+(?:/[^\"'`\\s<>]*)?|" +
+	"[A-Za-z0-9_\\-./]+/[A-Za-z0-9_\\-.]+\\.(?:php|aspx?|jsp|json|action|html?|js|txt|xml)(?:\\?[^\"'`\\s<>]*)?" +
+	")[\"'`]")
+
+// Scripts fetches the javascript files linked from a target page and
+// returns the urls of the ones on the host of the target along with the
+// endpoints found in them which are on the host of the target.
+//
+// The scripts of other hosts, such as bundles served from a cdn, are
+// fetched for their endpoints but not returned since they are not in
+// scope. Relative endpoints are resolved from the target page, as the
+// scripts use them from the page loading them.
+func (c *Crawler) Scripts(target string) (scripts, endpoints []string, err error) {
+	start, err := url.Parse(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := c.makeClient(start)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer client.CloseIdleConnections()
+
+	body, err := c.fetch(client, start.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	links := ParseScripts(start, body)
+	body.Close()
+
+	var fetched int
+	seen := make(map[string]struct{})
+	for _, link := range links {
+		if _, ok := seen[link.String()]; ok || fetched >= c.options.MaxURLs {
+			continue
+		}
+		seen[link.String()] = struct{}{}
+		fetched++
+		if link.Host == start.Host {
+			scripts = append(scripts, link.String())
+		}
+
+		body, err := c.fetch(client, link.String())
+		if err != nil {
+			continue
+		}
+		code, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			continue
+		}
+		for _, endpoint := range ParseEndpoints(string(code)) {
+			link, err := start.Parse(endpoint)
+			if err != nil || link.Host != start.Host || link.Scheme != start.Scheme {
+				continue
+			}
+			link.Fragment = ""
+			if _, ok := seen[link.String()]; ok || len(endpoints) >= c.options.MaxURLs {
+				continue
+			}
+			seen[link.String()] = struct{}{}
+			endpoints = append(endpoints, link.String())
+		}
+	}
+	return scripts, endpoints, nil
+}
+
+// ParseScripts parses the urls of the javascript files loaded by a html
+// page, resolved from the page url.
+func ParseScripts(page *url.URL, body io.Reader) []*url.URL {
+	var scripts []*url.URL
+
+	tokenizer := html.NewTokenizer(body)
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return scripts
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		if token.Data != "script" {
+			continue
+		}
+		for _, attr := range token.Attr {
+			if attr.Key != "src" || attr.Val == "" {
+				continue
+			}
+			link, err := page.Parse(strings.TrimSpace(attr.Val))
+			if err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+				continue
+			}
+			link.Fragment = ""
+			scripts = append(scripts, link)
+		}
+	}
+}
+
+// ParseEndpoints parses the endpoints referenced by quoted strings in
+// javascript code, in order of appearance.
+func ParseEndpoints(code string) []string {
+	var endpoints []string
+	for _, match := range endpointRegex.FindAllStringSubmatch(code, -1) {
+		endpoints = append(endpoints, match[1])
+	}
+	return endpoints
+}
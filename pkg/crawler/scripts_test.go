@@ -0,0 +1,61 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScripts(t *testing.T) {
+	page, _ := url.Parse("https://example.com/app/")
+	scripts := ParseScripts(page, strings.NewReader(`<html><head>
+<script src="main.js"></script>
+<script src="https://cdn.example.net/lib.js#x"></script>
+<script>var inline = 1;</script>
+</head></html>`))
+
+	var URLs []string
+	for _, script := range scripts {
+		URLs = append(URLs, script.String())
+	}
+	require.Equal(t, []string{"https://example.com/app/main.js", "https://cdn.example.net/lib.js"}, URLs, "Could not parse scripts")
+}
+
+func TestParseEndpoints(t *testing.T) {
+	endpoints := ParseEndpoints(`fetch("/api/v1/users?id=1");
+var base = 'https://api.example.com/v2';
+$.get(` + "`" + `static/data.json` + "`" + `);
+var type = "text/html", root = "/", text = "hello world";`)
+
+	require.Equal(t, []string{"/api/v1/users?id=1", "https://api.example.com/v2", "static/data.json"}, endpoints, "Could not parse endpoints")
+}
+
+func TestScripts(t *testing.T) {
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `fetch("/api/cdn"); fetch("https://other.example.com/api");`)
+	}))
+	defer cdn.Close()
+	// The cdn is reached with another name to be on another host
+	cdnURL := strings.Replace(cdn.URL, "127.0.0.1", "localhost", 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<script src="/main.js"></script><script src="%s/lib.js"></script>`, cdnURL)
+	})
+	mux.HandleFunc("/main.js", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `fetch("/api/main");`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	options := DefaultOptions
+	scripts, endpoints, err := New(&options).Scripts(server.URL + "/")
+	require.Nil(t, err, "Could not fetch scripts")
+	require.Equal(t, []string{server.URL + "/main.js"}, scripts, "Could not skip scripts of other hosts")
+	require.Equal(t, []string{server.URL + "/api/main", server.URL + "/api/cdn"}, endpoints, "Could not get endpoints of the scripts")
+}
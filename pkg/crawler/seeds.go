@@ -0,0 +1,179 @@
+package crawler
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxSitemaps is the maximum number of sitemaps read per target,
+// including the ones referenced by sitemap indexes.
+const maxSitemaps = 10
+
+// Seed returns the urls of a target listed in its robots.txt and sitemaps.
+func (c *Crawler) Seed(target string) ([]string, error) {
+	start, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.makeClient(start)
+	if err != nil {
+		return nil, err
+	}
+	defer client.CloseIdleConnections()
+
+	seen := make(map[string]struct{})
+	var results []string
+	add := func(link *url.URL) {
+		if link.Host != start.Host || len(results) >= c.options.MaxURLs {
+			return
+		}
+		link.Fragment = ""
+		if _, ok := seen[link.String()]; ok {
+			return
+		}
+		seen[link.String()] = struct{}{}
+		results = append(results, link.String())
+	}
+
+	root := &url.URL{Scheme: start.Scheme, Host: start.Host, Path: "/"}
+	sitemaps := []string{root.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String()}
+	// Only the sitemaps on the host of the target are read
+	addSitemaps := func(values []string) {
+		for _, value := range values {
+			if link, err := root.Parse(value); err == nil && link.Host == start.Host {
+				sitemaps = append(sitemaps, link.String())
+			}
+		}
+	}
+
+	// Read the paths and sitemaps listed in robots.txt
+	robots := root.ResolveReference(&url.URL{Path: "/robots.txt"})
+	if body, err := c.fetch(client, robots.String()); err == nil {
+		paths, robotsSitemaps := ParseRobots(body)
+		body.Close()
+
+		for _, path := range paths {
+			if link, err := root.Parse(path); err == nil {
+				add(link)
+			}
+		}
+		addSitemaps(robotsSitemaps)
+	}
+
+	// Read the urls listed in the sitemaps, following sitemap indexes
+	read := make(map[string]struct{})
+	for len(sitemaps) > 0 && len(read) < maxSitemaps {
+		sitemap := sitemaps[0]
+		sitemaps = sitemaps[1:]
+		if _, ok := read[sitemap]; ok {
+			continue
+		}
+		read[sitemap] = struct{}{}
+
+		body, err := c.fetch(client, sitemap)
+		if err != nil {
+			continue
+		}
+		locations, indexes := ParseSitemap(body)
+		body.Close()
+
+		for _, location := range locations {
+			if link, err := url.Parse(location); err == nil {
+				add(link)
+			}
+		}
+		addSitemaps(indexes)
+	}
+	return results, nil
+}
+
+// fetch returns the body of a page if it was found
+func (c *Crawler) fetch(client *http.Client, URL string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Nuclei (@pdiscoveryio)")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(resp.Body, c.options.MaxBodySize), resp.Body}, nil
+}
+
+// ParseRobots parses the paths and sitemaps listed in a robots.txt file.
+//
+// Paths containing wildcards are truncated before the first wildcard.
+func ParseRobots(body io.Reader) (paths, sitemaps []string) {
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+
+		switch strings.ToLower(strings.TrimSpace(parts[0])) {
+		case "allow", "disallow":
+			if i := strings.IndexAny(value, "*$"); i != -1 {
+				value = value[:i]
+			}
+			if strings.HasPrefix(value, "/") && value != "/" {
+				paths = append(paths, value)
+			}
+		case "sitemap":
+			if value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		}
+	}
+	return paths, sitemaps
+}
+
+// sitemap is a sitemap or a sitemap index document
+type sitemap struct {
+	URLs     []sitemapLocation `xml:"url"`
+	Sitemaps []sitemapLocation `xml:"sitemap"`
+}
+
+type sitemapLocation struct {
+	Location string `xml:"loc"`
+}
+
+// ParseSitemap parses the page urls and the nested sitemap urls of a sitemap.
+func ParseSitemap(body io.Reader) (locations, sitemaps []string) {
+	document := &sitemap{}
+	if err := xml.NewDecoder(body).Decode(document); err != nil {
+		return nil, nil
+	}
+	for _, location := range document.URLs {
+		if value := strings.TrimSpace(location.Location); value != "" {
+			locations = append(locations, value)
+		}
+	}
+	for _, location := range document.Sitemaps {
+		if value := strings.TrimSpace(location.Location); value != "" {
+			sitemaps = append(sitemaps, value)
+		}
+	}
+	return locations, sitemaps
+}
@@ -0,0 +1,67 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRobots(t *testing.T) {
+	paths, sitemaps := ParseRobots(strings.NewReader(`User-agent: *
+Disallow: /admin/ # private
+Disallow: /search*q=
+Allow: /
+Sitemap: https://example.com/sitemap-pages.xml`))
+
+	require.Equal(t, []string{"/admin/", "/search"}, paths, "Could not parse robots paths")
+	require.Equal(t, []string{"https://example.com/sitemap-pages.xml"}, sitemaps, "Could not parse robots sitemaps")
+}
+
+func TestParseSitemap(t *testing.T) {
+	locations, _ := ParseSitemap(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>https://example.com/a</loc></url>
+	<url><loc> https://example.com/b </loc></url>
+</urlset>`))
+	require.Equal(t, []string{"https://example.com/a", "https://example.com/b"}, locations, "Could not parse sitemap urls")
+
+	_, sitemaps := ParseSitemap(strings.NewReader(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>https://example.com/sitemap-1.xml</loc></sitemap>
+</sitemapindex>`))
+	require.Equal(t, []string{"https://example.com/sitemap-1.xml"}, sitemaps, "Could not parse sitemap index")
+}
+
+func TestSeed(t *testing.T) {
+	var external int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&external, 1)
+	}))
+	defer other.Close()
+	// The other server is reached with another name to be on another host
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Disallow: /admin\nSitemap: %s/sitemap.xml\nSitemap: /pages.xml\n", otherURL)
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<sitemapindex><sitemap><loc>%s/index.xml</loc></sitemap></sitemapindex>`, otherURL)
+	})
+	mux.HandleFunc("/pages.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<urlset><url><loc>%s/page</loc></url><url><loc>%s/page</loc></url></urlset>`, server.URL, otherURL)
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	options := DefaultOptions
+	results, err := New(&options).Seed(server.URL)
+	require.Nil(t, err, "Could not seed target")
+	require.Equal(t, []string{server.URL + "/admin", server.URL + "/page"}, results, "Could not seed urls of the target")
+	require.Equal(t, int32(0), atomic.LoadInt32(&external), "Could read sitemaps of other hosts")
+}
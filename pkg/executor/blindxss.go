@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/pkg/templates"
+	"gopkg.in/yaml.v2"
+)
+
+// BlindXSSVariable is the template variable replaced with the blind xss payload.
+const BlindXSSVariable = "blindxss"
+
+// correlationResultType is the type of the records of the correlation log,
+// which are injections of blind xss payloads and not findings.
+const correlationResultType = "blindxss"
+
+// BlindXSS contains the user configured blind xss callback payload.
+type BlindXSS struct {
+	// Payload is the payload injected in place of {{blindxss}}.
+	//
+	// {{id}} in the payload is replaced with a correlation id unique
+	// to the template and target, so that callbacks can be traced
+	// back to the request that triggered them.
+	Payload string `yaml:"payload"`
+}
+
+// LoadBlindXSS loads the blind xss configuration from a yaml file.
+func LoadBlindXSS(file string) (*BlindXSS, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config := &BlindXSS{}
+	if err := yaml.NewDecoder(f).Decode(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// makePayload returns a payload with a new correlation id
+func (b *BlindXSS) makePayload() (payload, correlationID string, err error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	correlationID = hex.EncodeToString(id)
+	return strings.Replace(b.Payload, "{{id}}", correlationID, -1), correlationID, nil
+}
+
+// CorrelationLog records the requests injected with blind xss payloads
+// to a file, one json record per line, so that the callbacks received
+// can be traced back to them. The records are kept apart from the
+// results as they aren't findings. A nil log discards the records.
+type CorrelationLog struct {
+	mutex *sync.Mutex
+	file  *os.File
+}
+
+// NewCorrelationLog opens a correlation log, appending to the records
+// of the previous scans so that their callbacks can still be traced.
+func NewCorrelationLog(path string) (*CorrelationLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &CorrelationLog{mutex: &sync.Mutex{}, file: file}, nil
+}
+
+// Write records a request of a template injected with the payload
+// of a correlation id.
+func (l *CorrelationLog) Write(template *templates.Template, URL, correlationID string) {
+	if l == nil {
+		return
+	}
+
+	result := newResult(template, correlationResultType, URL, nil, nil)
+	result.CorrelationID = correlationID
+	data := formatOutputJSON(result)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, err := l.file.Write(data); err != nil {
+		gologger.Warningf("Could not write correlation log: %s\n", err)
+	}
+}
+
+// Close closes the file of the log.
+func (l *CorrelationLog) Close() {
+	if l == nil {
+		return
+	}
+	l.file.Close()
+}
@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"container/list"
+	"sync"
+)
+
+// RequestBudget limits the number of requests sent to each host.
+//
+// The counts of the least recently used hosts are dropped once the
+// budget tracks its maximum number of hosts, if any, bounding its
+// memory at the cost of a new budget for these hosts if they are
+// requested again. A nil budget allows an unlimited number of requests.
+type RequestBudget struct {
+	max      int
+	maxHosts int
+	mutex    *sync.Mutex
+	counts   map[string]*list.Element
+	// recent lists the counts of the hosts, most recently used first
+	recent *list.List
+}
+
+// hostCount is the number of requests sent to a host
+type hostCount struct {
+	host  string
+	count int
+}
+
+// NewRequestBudget creates a budget allowing max requests per host.
+func NewRequestBudget(max int) *RequestBudget {
+	return NewBoundedRequestBudget(max, 0)
+}
+
+// NewBoundedRequestBudget creates a budget allowing max requests per
+// host, keeping the counts of at most maxHosts hosts, or of all the
+// hosts if zero.
+func NewBoundedRequestBudget(max, maxHosts int) *RequestBudget {
+	return &RequestBudget{
+		max:      max,
+		maxHosts: maxHosts,
+		mutex:    &sync.Mutex{},
+		counts:   make(map[string]*list.Element),
+		recent:   list.New(),
+	}
+}
+
+// Take consumes a request from the budget of a host, returning
+// false if the budget of the host is exhausted.
+func (b *RequestBudget) Take(host string) bool {
+	if b == nil {
+		return true
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	element, ok := b.counts[host]
+	if ok {
+		b.recent.MoveToFront(element)
+	} else {
+		element = b.recent.PushFront(&hostCount{host: host})
+		b.counts[host] = element
+		if b.maxHosts > 0 && b.recent.Len() > b.maxHosts {
+			oldest := b.recent.Remove(b.recent.Back()).(*hostCount)
+			delete(b.counts, oldest.host)
+		}
+	}
+
+	count := element.Value.(*hostCount)
+	if count.count >= b.max {
+		return false
+	}
+	count.count++
+	return true
+}
@@ -0,0 +1,31 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestBudget(t *testing.T) {
+	budget := NewRequestBudget(2)
+	require.True(t, budget.Take("a"), "Could not take request")
+	require.True(t, budget.Take("a"), "Could not take request")
+	require.False(t, budget.Take("a"), "Could take request over budget")
+	require.True(t, budget.Take("b"), "Could not take request of other host")
+
+	var unlimited *RequestBudget
+	require.True(t, unlimited.Take("a"), "Could not take request without budget")
+}
+
+func TestBoundedRequestBudget(t *testing.T) {
+	budget := NewBoundedRequestBudget(1, 2)
+	require.True(t, budget.Take("a"), "Could not take request")
+	require.True(t, budget.Take("b"), "Could not take request")
+	require.False(t, budget.Take("a"), "Could take request over budget")
+
+	// b is now the least recently used host and is dropped for c
+	require.True(t, budget.Take("c"), "Could not take request")
+	require.Len(t, budget.counts, 2, "Could not bound the hosts of the budget")
+	require.False(t, budget.Take("a"), "Could take request over budget of kept host")
+	require.True(t, budget.Take("b"), "Could not take request of dropped host")
+}
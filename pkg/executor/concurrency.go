@@ -0,0 +1,145 @@
+package executor
+
+import (
+	"sync"
+)
+
+// ConcurrencyController adapts the number of targets processed
+// concurrently to the error rates of the scan, both globally and for
+// each host.
+//
+// The limits are increased by one after a window of requests without
+// errors and halved after a window with too many errors, such as
+// timeouts or refused connections. A nil controller never limits.
+type ConcurrencyController struct {
+	cond  *sync.Cond
+	max   int
+	total *concurrencyState
+	hosts map[string]*concurrencyState
+	// onChange is optionally called when the global limit changes
+	onChange func(limit int)
+}
+
+// concurrencyState is the adaptive limit of the global or host concurrency
+type concurrencyState struct {
+	limit  int
+	active int
+	// done and failed are the completed and failed requests of the window
+	done   int
+	failed int
+}
+
+const (
+	// minConcurrencyWindow is the minimum number of completed requests
+	// the error rate is computed over before adapting a limit
+	minConcurrencyWindow = 10
+	// maxErrorRate is the error rate of a window above which a limit is halved
+	maxErrorRate = 0.1
+	// increaseErrorRate is the error rate of a window below which a
+	// limit is increased
+	increaseErrorRate = 0.02
+)
+
+// NewConcurrencyController creates a new controller starting at the
+// initial concurrency and scaling it between one and max, calling
+// onChange, if any, with the new global limit when it changes.
+func NewConcurrencyController(initial, max int, onChange func(limit int)) *ConcurrencyController {
+	if initial > max {
+		initial = max
+	}
+	return &ConcurrencyController{
+		cond:     sync.NewCond(&sync.Mutex{}),
+		max:      max,
+		total:    &concurrencyState{limit: initial},
+		hosts:    make(map[string]*concurrencyState),
+		onChange: onChange,
+	}
+}
+
+// Acquire waits until a target of a host can be processed under the
+// global and host limits.
+func (c *ConcurrencyController) Acquire(host string) {
+	if c == nil {
+		return
+	}
+
+	c.cond.L.Lock()
+	defer c.cond.L.Unlock()
+
+	state, ok := c.hosts[host]
+	if !ok {
+		state = &concurrencyState{limit: c.max}
+		c.hosts[host] = state
+	}
+	for c.total.active >= c.total.limit || state.active >= state.limit {
+		c.cond.Wait()
+	}
+	c.total.active++
+	state.active++
+}
+
+// Release releases a target of a host acquired before, adapting the
+// limits with the error of processing the target, if any.
+func (c *ConcurrencyController) Release(host string, err error) {
+	if c == nil {
+		return
+	}
+
+	c.cond.L.Lock()
+	state := c.hosts[host]
+	state.active--
+	state.adapt(err != nil, c.max)
+	// Only keep the hosts which are limited, to bound the memory used
+	if state.active == 0 && state.limit == c.max && state.failed == 0 {
+		delete(c.hosts, host)
+	}
+
+	c.total.active--
+	previous := c.total.limit
+	c.total.adapt(err != nil, c.max)
+	limit := c.total.limit
+	c.cond.L.Unlock()
+
+	c.cond.Broadcast()
+	if limit != previous && c.onChange != nil {
+		c.onChange(limit)
+	}
+}
+
+// Limit returns the current global concurrency limit.
+func (c *ConcurrencyController) Limit() int {
+	if c == nil {
+		return 0
+	}
+
+	c.cond.L.Lock()
+	defer c.cond.L.Unlock()
+	return c.total.limit
+}
+
+// adapt records a completed request and adapts the limit once the window,
+// of the larger of minConcurrencyWindow and the limit requests, is complete.
+func (s *concurrencyState) adapt(failed bool, max int) {
+	s.done++
+	if failed {
+		s.failed++
+	}
+	window := s.limit
+	if window < minConcurrencyWindow {
+		window = minConcurrencyWindow
+	}
+	if s.done < window {
+		return
+	}
+
+	rate := float64(s.failed) / float64(s.done)
+	switch {
+	case rate > maxErrorRate:
+		if s.limit = s.limit / 2; s.limit < 1 {
+			s.limit = 1
+		}
+	case rate < increaseErrorRate && s.limit < max:
+		s.limit++
+	}
+	s.done, s.failed = 0, 0
+}
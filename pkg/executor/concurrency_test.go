@@ -0,0 +1,32 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyController(t *testing.T) {
+	controller := NewConcurrencyController(4, 8, nil)
+
+	// A window without errors increases the limit
+	for i := 0; i < minConcurrencyWindow; i++ {
+		controller.Acquire("a")
+		controller.Release("a", nil)
+	}
+	require.Equal(t, 5, controller.Limit(), "Could not increase limit")
+
+	// A window with too many errors halves the limit
+	for i := 0; i < minConcurrencyWindow; i++ {
+		controller.Acquire("b")
+		controller.Release("b", errors.New("timeout"))
+	}
+	require.Equal(t, 2, controller.Limit(), "Could not decrease limit")
+	require.Equal(t, 4, controller.hosts["b"].limit, "Could not decrease host limit")
+	require.NotContains(t, controller.hosts, "a", "Could not drop unlimited host")
+
+	var nilController *ConcurrencyController
+	nilController.Acquire("a")
+	nilController.Release("a", nil)
+}
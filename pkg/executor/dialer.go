@@ -0,0 +1,61 @@
+package executor
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// DefaultKeepAlive is the default interval between the tcp keep-alive probes.
+const DefaultKeepAlive = 30
+
+// happyEyeballsDelay is the delay before falling back to the other ip
+// family when connecting to dual-stack hosts (RFC 6555).
+const happyEyeballsDelay = 300 * time.Millisecond
+
+// DialerOptions contains configuration options for the dialer
+// used to connect to the hosts.
+type DialerOptions struct {
+	// ConnectTimeout is the seconds to wait for a connection to be
+	// established, or the timeout of the request if 0.
+	ConnectTimeout int
+	// KeepAlive is the seconds between the tcp keep-alive probes of
+	// the connections, or disables them if negative.
+	KeepAlive int
+	// NoHappyEyeballs disables racing ipv4 and ipv6 connections to
+	// dual-stack hosts, only using the first address resolved.
+	NoHappyEyeballs bool
+}
+
+// makeDialer creates a dialer from the options, using the timeout
+// of the request as connect timeout if none was specified.
+func makeDialer(options *DialerOptions, timeout int) *net.Dialer {
+	if options == nil {
+		options = &DialerOptions{KeepAlive: DefaultKeepAlive}
+	}
+
+	connectTimeout := options.ConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = timeout
+	}
+	dialer := &net.Dialer{
+		Timeout:       time.Duration(connectTimeout) * time.Second,
+		KeepAlive:     time.Duration(options.KeepAlive) * time.Second,
+		FallbackDelay: happyEyeballsDelay,
+	}
+	if options.NoHappyEyeballs {
+		dialer.FallbackDelay = -1
+	}
+	return dialer
+}
+
+// makeDialContext returns the function dialing the hosts with a dialer,
+// resolving them through the secure resolver if any.
+func makeDialContext(dialer *net.Dialer, resolver *SecureResolver) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if resolver == nil {
+		return dialer.DialContext
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		return resolver.dial(ctx, dialer, network, address)
+	}
+}
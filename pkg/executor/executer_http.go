@@ -1,44 +1,86 @@
 package executor
 
 import (
-	"bufio"
-	"crypto/tls"
-	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/pkg/extractors"
 	"github.com/projectdiscovery/nuclei/pkg/matchers"
 	"github.com/projectdiscovery/nuclei/pkg/requests"
 	"github.com/projectdiscovery/nuclei/pkg/templates"
 	"github.com/projectdiscovery/retryablehttp-go"
-	"golang.org/x/net/proxy"
+)
+
+const (
+	// ProxyAuthBasic authenticates to http proxies with basic credentials
+	ProxyAuthBasic = "basic"
+	// ProxyAuthNTLM authenticates to http proxies with ntlm
+	ProxyAuthNTLM = "ntlm"
 )
 
 // HTTPExecutor is client for performing HTTP requests
 // for a template.
 type HTTPExecutor struct {
-	httpClient  *retryablehttp.Client
-	template    *templates.Template
-	httpRequest *requests.HTTPRequest
-	writer      *bufio.Writer
-	outputMutex *sync.Mutex
+	httpClient     *retryablehttp.Client
+	template       *templates.Template
+	httpRequest    *requests.HTTPRequest
+	output         *OutputWriter
+	extracts       *ExtractWriter
+	jsonOutput     bool
+	budget         *RequestBudget
+	throttle       *HostThrottle
+	blindXSS       *BlindXSS
+	correlationLog *CorrelationLog
+	envValues      map[string]interface{}
+	spoof          requests.SpoofProfile
+	maxBodySize    int64
 }
 
 // HTTPOptions contains configuration options for the HTTP executor.
 type HTTPOptions struct {
 	Template      *templates.Template
 	HTTPRequest   *requests.HTTPRequest
-	Writer        *bufio.Writer
+	Output        *OutputWriter
 	Timeout       int
 	Retries       int
 	ProxyURL      string
 	ProxySocksURL string
+	// ProxyAuth is the authentication scheme of the http proxy, basic or ntlm.
+	//
+	// Basic credentials are sent for all the requests, while ntlm tunnels
+	// all the connections through the proxy.
+	ProxyAuth string
+	// JSON specifies whether results should be written as json lines.
+	JSON bool
+	// Budget optionally limits the number of requests sent to each host.
+	Budget *RequestBudget
+	// Throttle optionally slows down the requests to the hosts blocking them.
+	Throttle *HostThrottle
+	// BlindXSS optionally contains the blind xss payload to inject.
+	BlindXSS *BlindXSS
+	// CorrelationLog optionally records the requests injected
+	// with the blind xss payload.
+	CorrelationLog *CorrelationLog
+	// EnvValues optionally contains the values of the allowed
+	// environment variables, as returned by requests.EnvValues.
+	EnvValues map[string]interface{}
+	// Resolver optionally resolves the hosts through a secure resolver.
+	Resolver *SecureResolver
+	// Dialer optionally configures the connections to the hosts.
+	Dialer *DialerOptions
+	// Spoof optionally sets the ip spoofing headers for templates
+	// not specifying a spoof profile.
+	Spoof requests.SpoofProfile
+	// MaxBodySize optionally limits the number of bytes of the
+	// responses read, the rest of the body being discarded.
+	MaxBodySize int64
+	// Extracts optionally writes the extracted values to files.
+	Extracts *ExtractWriter
 }
 
 // NewHTTPExecutor creates a new HTTP executor from a template
@@ -59,26 +101,99 @@ func NewHTTPExecutor(options *HTTPOptions) (*HTTPExecutor, error) {
 	client.CheckRetry = retryablehttp.HostSprayRetryPolicy()
 
 	executer := &HTTPExecutor{
-		httpClient:  client,
-		template:    options.Template,
-		httpRequest: options.HTTPRequest,
-		outputMutex: &sync.Mutex{},
-		writer:      options.Writer,
+		httpClient:     client,
+		template:       options.Template,
+		httpRequest:    options.HTTPRequest,
+		jsonOutput:     options.JSON,
+		budget:         options.Budget,
+		throttle:       options.Throttle,
+		blindXSS:       options.BlindXSS,
+		correlationLog: options.CorrelationLog,
+		envValues:      options.EnvValues,
+		spoof:          options.Spoof,
+		output:         options.Output,
+		extracts:       options.Extracts,
+		maxBodySize:    options.MaxBodySize,
 	}
 	return executer, nil
 }
 
 // ExecuteHTTP executes the HTTP request on a URL
 func (e *HTTPExecutor) ExecuteHTTP(URL string) error {
+	dynamicValues := make(map[string]interface{})
+	for k, v := range e.envValues {
+		dynamicValues[k] = v
+	}
+
+	// Inject a blind xss payload with a new correlation id if used
+	var correlationID string
+	if e.blindXSS != nil && e.httpRequest.UsesVariable(BlindXSSVariable) {
+		payload, id, err := e.blindXSS.makePayload()
+		if err != nil {
+			return errors.Wrap(err, "could not make blind xss payload")
+		}
+		dynamicValues[BlindXSSVariable] = payload
+		correlationID = id
+	}
+
 	// Compile each request for the template based on the URL
-	compiledRequest, err := e.httpRequest.MakeHTTPRequest(URL)
+	compiledRequest, err := e.httpRequest.MakeHTTPRequest(URL, dynamicValues)
 	if err != nil {
 		return errors.Wrap(err, "could not make http request")
 	}
 
+	var previousResp *http.Response
+	var previousBody string
+
 	// Send the request to the target servers
 mainLoop:
-	for _, req := range compiledRequest {
+	for i, req := range compiledRequest {
+		// Wait between the requests of the block if asked
+		if i > 0 {
+			if delay := e.httpRequest.GetDelay(); delay > 0 {
+				time.Sleep(delay)
+			}
+		}
+
+		// Build the steps depending on the previous response
+		if req == nil {
+			if previousResp == nil {
+				return errors.New("no previous response for form step")
+			}
+			req, err = e.httpRequest.MakeFormRequest(i, URL, dynamicValues, previousResp, previousBody)
+			if err != nil {
+				return errors.Wrap(err, "could not make form request")
+			}
+		}
+
+		// Refresh the anti-csrf token of the request if asked
+		if e.httpRequest.CSRF != nil {
+			var ok bool
+			req, ok, err = e.refreshCSRFToken(URL, req)
+			if err != nil {
+				return errors.Wrap(err, "could not refresh csrf token")
+			}
+			if !ok {
+				return nil
+			}
+		}
+
+		e.httpRequest.ApplySpoof(req.Header, e.spoof)
+
+		// Slow down the requests to the hosts blocking the scan, and
+		// stop once the request budget of the host is exhausted
+		if !e.allowRequest(req.URL.Host) {
+			return nil
+		}
+
+		// Record the injection so that callbacks can be correlated
+		if correlationID != "" {
+			e.correlationLog.Write(e.template, req.URL.String(), correlationID)
+		}
+
+		trace := newHTTPTrace(req)
+		trace.Start()
+
 		resp, err := e.httpClient.Do(req)
 		if err != nil {
 			if resp != nil {
@@ -87,18 +202,38 @@ mainLoop:
 			return errors.Wrap(err, "could not make http request")
 		}
 
-		data, err := ioutil.ReadAll(resp.Body)
+		var reader io.Reader = resp.Body
+		if e.maxBodySize > 0 {
+			reader = io.LimitReader(resp.Body, e.maxBodySize)
+		}
+		data, err := ioutil.ReadAll(reader)
 		if err != nil {
 			io.Copy(ioutil.Discard, resp.Body)
 			resp.Body.Close()
 			return errors.Wrap(err, "could not read http body")
 		}
 		resp.Body.Close()
+		trace.Done()
 
 		// Convert response body from []byte to string with zero copy
 		body := unsafeToString(data)
+		e.throttle.Observe(req.URL.Host, resp, body)
+		previousResp, previousBody = resp, body
+
+		// Skip the responses of the mime types the request doesn't apply to
+		if len(e.httpRequest.ContentType) > 0 && !e.httpRequest.MatchesContentType(matchers.GetContentType(resp, body)) {
+			continue
+		}
 
 		var headers string
+		var htmlParsed, jwtParsed bool
+		responseData := trace.toMap()
+		responseData["redirect_cookies"] = getRedirectCookies(resp)
+		if e.httpRequest.GRPC != nil {
+			addGRPCValues(responseData, resp, body)
+		}
+		conn := trace.getConnectionInfo(resp)
+		var matcherNames []string
 		matcherCondition := e.httpRequest.GetMatchersCondition()
 		for _, matcher := range e.httpRequest.Matchers {
 			// Only build the headers string if the matcher asks for it
@@ -106,18 +241,30 @@ mainLoop:
 			if part == matchers.AllPart || part == matchers.HeaderPart && headers == "" {
 				headers = headersToString(resp.Header)
 			}
+			// Only parse the html if the matcher asks for it
+			if (part == matchers.TitlePart || part == matchers.MetaPart) && !htmlParsed {
+				addHTMLValues(responseData, body)
+				htmlParsed = true
+			}
+			// Only decode the json web tokens if the matcher asks for them
+			if matcher.UsesJWT() && !jwtParsed {
+				addJWTValues(responseData, resp.Header, body)
+				jwtParsed = true
+			}
 
 			// Check if the matcher matched
-			if !matcher.Match(resp, body, headers) {
+			if !matcher.Match(resp, body, headers, responseData) {
 				// If the condition is AND we haven't matched, try next request.
 				if matcherCondition == matchers.ANDCondition {
 					continue mainLoop
 				}
 			} else {
+				matcherNames = appendMatcherName(matcherNames, matcher)
+
 				// If the matcher has matched, and its an OR
 				// write the first output then move to next matcher.
 				if matcherCondition == matchers.ORCondition && len(e.httpRequest.Extractors) == 0 {
-					e.writeOutputHTTP(req, matcher, nil)
+					e.writeOutputHTTP(req, conn, appendMatcherName(nil, matcher), nil)
 				}
 			}
 		}
@@ -130,25 +277,83 @@ mainLoop:
 			if part == extractors.AllPart || part == extractors.HeaderPart && headers == "" {
 				headers = headersToString(resp.Header)
 			}
-			for match := range extractor.Extract(body, headers) {
+			if (part == extractors.TitlePart || part == extractors.MetaPart) && !htmlParsed {
+				addHTMLValues(responseData, body)
+				htmlParsed = true
+			}
+			if part == extractors.JWTPart && !jwtParsed {
+				addJWTValues(responseData, resp.Header, body)
+				jwtParsed = true
+			}
+			matches := extractor.Extract(body, headers, responseData)
+			for match := range matches {
 				extractorResults = append(extractorResults, match)
 			}
+			e.extracts.Write(getExtractorName(e.template, extractor), matches)
 		}
 
-		// Write a final string of output if matcher type is
-		// AND or if we have extractors for the mechanism too.
-		if len(e.httpRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
-			e.writeOutputHTTP(req, nil, extractorResults)
+		// Write a final string of output if matcher type is AND or if we
+		// have extractors for the mechanism too, as long as a matcher
+		// matched or a value was extracted for the OR condition.
+		if matcherCondition == matchers.ANDCondition {
+			e.writeOutputHTTP(req, conn, matcherNames, extractorResults)
+		} else if len(e.httpRequest.Extractors) > 0 && (len(matcherNames) > 0 || len(extractorResults) > 0) {
+			e.writeOutputHTTP(req, conn, matcherNames, extractorResults)
 		}
 	}
 	return nil
 }
 
-// Close closes the http executor for a template.
-func (e *HTTPExecutor) Close() {
-	e.outputMutex.Lock()
-	e.writer.Flush()
-	e.outputMutex.Unlock()
+// refreshCSRFToken fetches a fresh anti-csrf token from the source
+// page and sets it on the request.
+//
+// The source request counts against the request budget and is slowed
+// down like the other requests, false being returned if it can't be sent.
+func (e *HTTPExecutor) refreshCSRFToken(URL string, req *retryablehttp.Request) (*retryablehttp.Request, bool, error) {
+	sourceReq, err := e.httpRequest.CSRF.MakeSourceRequest(URL, req)
+	if err != nil {
+		return nil, false, err
+	}
+	if !e.allowRequest(sourceReq.URL.Host) {
+		return nil, false, nil
+	}
+
+	resp, err := e.httpClient.Do(sourceReq)
+	if err != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, false, err
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, false, err
+	}
+	body := unsafeToString(data)
+	e.throttle.Observe(sourceReq.URL.Host, resp, body)
+
+	token, ok := e.httpRequest.CSRF.ExtractToken(resp, body)
+	if !ok {
+		return nil, false, errors.New("no csrf token found in source page")
+	}
+	req, err = e.httpRequest.CSRF.ApplyToken(req, token)
+	return req, err == nil, err
+}
+
+// allowRequest waits until a request can be sent to a host, returning
+// false if the host is paused for blocking the scan or the request
+// budget of the host is exhausted.
+func (e *HTTPExecutor) allowRequest(host string) bool {
+	if !e.throttle.Wait(host) {
+		gologger.Verbosef("Requests paused for blocking host %s\n", e.template.ID, host)
+		return false
+	}
+	if !e.budget.Take(host) {
+		gologger.Verbosef("Request budget exhausted for %s\n", e.template.ID, host)
+		return false
+	}
+	return true
 }
 
 // makeHTTPClient creates a http client
@@ -159,56 +364,52 @@ func makeHTTPClient(proxyURL *url.URL, options *HTTPOptions) *retryablehttp.Clie
 	followRedirects := options.HTTPRequest.Redirects
 	maxRedirects := options.HTTPRequest.MaxRedirects
 
-	transport := &http.Transport{
-		MaxIdleConnsPerHost: -1,
-		TLSClientConfig: &tls.Config{
-			Renegotiation:      tls.RenegotiateOnceAsClient,
-			InsecureSkipVerify: true,
-		},
-		DisableKeepAlives: true,
-	}
-
-	// Attempts to overwrite the dial function with the socks proxied version
-	if options.ProxySocksURL != "" {
-		var proxyAuth *proxy.Auth
-		socksURL, err := url.Parse(options.ProxySocksURL)
-		if err == nil {
-			proxyAuth = &proxy.Auth{}
-			proxyAuth.User = socksURL.User.Username()
-			proxyAuth.Password, _ = socksURL.User.Password()
-		}
-		dialer, err := proxy.SOCKS5("tcp", fmt.Sprintf("%s:%s", socksURL.Hostname(), socksURL.Port()), proxyAuth, proxy.Direct)
-		if err == nil {
-			transport.Dial = dialer.Dial
-		}
-	}
+	transport := makeTransport(proxyURL, &TransportOptions{
+		Timeout:       options.Timeout,
+		ProxyURL:      options.ProxyURL,
+		ProxySocksURL: options.ProxySocksURL,
+		ProxyAuth:     options.ProxyAuth,
+		Resolver:      options.Resolver,
+		Dialer:        options.Dialer,
+	})
 
-	if proxyURL != nil {
-		transport.Proxy = http.ProxyURL(proxyURL)
+	// Send native grpc calls over http/2
+	var roundTripper http.RoundTripper = transport
+	if grpc := options.HTTPRequest.GRPC; grpc != nil && !grpc.Web {
+		roundTripper = makeGRPCTransport(transport)
 	}
 	return retryablehttp.NewWithHTTPClient(&http.Client{
-		Transport:     transport,
+		Transport:     roundTripper,
 		Timeout:       time.Duration(options.Timeout) * time.Second,
-		CheckRedirect: makeCheckRedirectFunc(followRedirects, maxRedirects),
+		CheckRedirect: makeCheckRedirectFunc(followRedirects, maxRedirects, options.HTTPRequest.RedirectCookies),
 	}, retryablehttpOptions)
 }
 
-type checkRedirectFunc func(_ *http.Request, requests []*http.Request) error
+type checkRedirectFunc func(req *http.Request, via []*http.Request) error
+
+func makeCheckRedirectFunc(followRedirects bool, maxRedirects int, redirectCookies bool) checkRedirectFunc {
+	return func(req *http.Request, via []*http.Request) error {
+		followRedirects, maxRedirects := followRedirects, maxRedirects
+
+		// Use the redirect options of the request step if it has any
+		if options, ok := requests.GetRedirectOptions(req.Context()); ok {
+			followRedirects, maxRedirects = options.Follow, options.Max
+		}
 
-func makeCheckRedirectFunc(followRedirects bool, maxRedirects int) checkRedirectFunc {
-	return func(_ *http.Request, requests []*http.Request) error {
 		if !followRedirects {
 			return http.ErrUseLastResponse
 		}
 		if maxRedirects == 0 {
-			if len(requests) > 10 {
-				return http.ErrUseLastResponse
-			}
-			return nil
+			maxRedirects = 10
 		}
-		if len(requests) > maxRedirects {
+		if len(via) > maxRedirects {
 			return http.ErrUseLastResponse
 		}
+
+		// Keep the cookies set during the redirects if asked
+		if redirectCookies {
+			addRedirectCookies(req, via)
+		}
 		return nil
 	}
 }
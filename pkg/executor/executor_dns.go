@@ -1,10 +1,9 @@
 package executor
 
 import (
-	"bufio"
-	"sync"
-
+	"github.com/miekg/dns"
 	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/pkg/matchers"
 	"github.com/projectdiscovery/nuclei/pkg/requests"
 	"github.com/projectdiscovery/nuclei/pkg/templates"
@@ -14,11 +13,13 @@ import (
 // DNSExecutor is a client for performing a DNS request
 // for a template.
 type DNSExecutor struct {
-	dnsClient   *retryabledns.Client
-	template    *templates.Template
-	dnsRequest  *requests.DNSRequest
-	writer      *bufio.Writer
-	outputMutex *sync.Mutex
+	dnsClient  dnsClient
+	template   *templates.Template
+	dnsRequest *requests.DNSRequest
+	output     *OutputWriter
+	extracts   *ExtractWriter
+	jsonOutput bool
+	budget     *RequestBudget
 }
 
 // DefaultResolvers contains the list of resolvers known to be trusted.
@@ -29,24 +30,60 @@ var DefaultResolvers = []string{
 	"8.8.4.4:53", // Google
 }
 
+// dnsClient is a client sending dns queries to resolvers.
+type dnsClient interface {
+	Do(msg *dns.Msg) (*dns.Msg, error)
+}
+
+// retryingDNSClient sends the queries of a dns client up to a number
+// of times until one succeeds, like the retryable dns client does.
+type retryingDNSClient struct {
+	client  dnsClient
+	retries int
+}
+
+// Do sends a dns query, retrying it on failure
+func (c *retryingDNSClient) Do(msg *dns.Msg) (resp *dns.Msg, err error) {
+	for i := 0; i == 0 || i < c.retries; i++ {
+		resp, err = c.client.Do(msg)
+		if err == nil {
+			return resp, nil
+		}
+	}
+	return nil, err
+}
+
 // DNSOptions contains configuration options for the DNS executor.
 type DNSOptions struct {
 	Template   *templates.Template
 	DNSRequest *requests.DNSRequest
-	Writer     *bufio.Writer
+	Output     *OutputWriter
+	// Extracts optionally writes the extracted values to files.
+	Extracts *ExtractWriter
+	// JSON specifies whether results should be written as json lines.
+	JSON bool
+	// Budget optionally limits the number of requests sent to each host.
+	Budget *RequestBudget
+	// Resolver optionally sends the queries through a secure resolver.
+	Resolver *SecureResolver
 }
 
 // NewDNSExecutor creates a new DNS executor from a template
 // and a DNS request query.
 func NewDNSExecutor(options *DNSOptions) *DNSExecutor {
-	dnsClient := retryabledns.New(DefaultResolvers, options.DNSRequest.Retries)
+	var dnsClient dnsClient = retryabledns.New(DefaultResolvers, options.DNSRequest.Retries)
+	if options.Resolver != nil {
+		dnsClient = &retryingDNSClient{client: options.Resolver, retries: options.DNSRequest.Retries}
+	}
 
 	executer := &DNSExecutor{
-		dnsClient:   dnsClient,
-		template:    options.Template,
-		dnsRequest:  options.DNSRequest,
-		writer:      options.Writer,
-		outputMutex: &sync.Mutex{},
+		dnsClient:  dnsClient,
+		template:   options.Template,
+		dnsRequest: options.DNSRequest,
+		output:     options.Output,
+		extracts:   options.Extracts,
+		jsonOutput: options.JSON,
+		budget:     options.Budget,
 	}
 	return executer
 }
@@ -67,12 +104,19 @@ func (e *DNSExecutor) ExecuteDNS(URL string) error {
 		return errors.Wrap(err, "could not make dns request")
 	}
 
+	// Stop once the request budget of the host is exhausted
+	if !e.budget.Take(domain) {
+		gologger.Verbosef("Request budget exhausted for %s\n", e.template.ID, domain)
+		return nil
+	}
+
 	// Send the request to the target servers
 	resp, err := e.dnsClient.Do(compiledRequest)
 	if err != nil {
 		return errors.Wrap(err, "could not send dns request")
 	}
 
+	var matcherNames []string
 	matcherCondition := e.dnsRequest.GetMatchersCondition()
 	for _, matcher := range e.dnsRequest.Matchers {
 		// Check if the matcher matched
@@ -82,10 +126,12 @@ func (e *DNSExecutor) ExecuteDNS(URL string) error {
 				return nil
 			}
 		} else {
+			matcherNames = appendMatcherName(matcherNames, matcher)
+
 			// If the matcher has matched, and its an OR
 			// write the first output then move to next matcher.
 			if matcherCondition == matchers.ORCondition && len(e.dnsRequest.Extractors) == 0 {
-				e.writeOutputDNS(domain, matcher, nil)
+				e.writeOutputDNS(domain, appendMatcherName(nil, matcher), nil)
 			}
 		}
 	}
@@ -94,22 +140,20 @@ func (e *DNSExecutor) ExecuteDNS(URL string) error {
 	// next task which is extraction of input from matchers.
 	var extractorResults []string
 	for _, extractor := range e.dnsRequest.Extractors {
-		for match := range extractor.ExtractDNS(resp.String()) {
+		matches := extractor.ExtractDNS(resp.String())
+		for match := range matches {
 			extractorResults = append(extractorResults, match)
 		}
+		e.extracts.Write(getExtractorName(e.template, extractor), matches)
 	}
 
-	// Write a final string of output if matcher type is
-	// AND or if we have extractors for the mechanism too.
-	if len(e.dnsRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
-		e.writeOutputDNS(domain, nil, extractorResults)
+	// Write a final string of output if matcher type is AND or if we
+	// have extractors for the mechanism too, as long as a matcher
+	// matched or a value was extracted for the OR condition.
+	if matcherCondition == matchers.ANDCondition {
+		e.writeOutputDNS(domain, matcherNames, extractorResults)
+	} else if len(e.dnsRequest.Extractors) > 0 && (len(matcherNames) > 0 || len(extractorResults) > 0) {
+		e.writeOutputDNS(domain, matcherNames, extractorResults)
 	}
 	return nil
 }
-
-// Close closes the dns executor for a template.
-func (e *DNSExecutor) Close() {
-	e.outputMutex.Lock()
-	e.writer.Flush()
-	e.outputMutex.Unlock()
-}
@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+)
+
+// extractFileRegex matches the characters replaced in the
+// names of the extractor files.
+var extractFileRegex = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// ExtractWriter writes the values extracted during the scan to a file
+// per extractor name in a directory, one unique value per line.
+//
+// The values already present in the files are kept, so that resumed
+// or repeated scans only add the new values. A nil writer discards
+// the values.
+type ExtractWriter struct {
+	dir   string
+	mutex *sync.Mutex
+	files map[string]*extractFile
+}
+
+// extractFile is a file of extracted values along with the values it contains
+type extractFile struct {
+	file   *os.File
+	writer *bufio.Writer
+	seen   map[string]struct{}
+}
+
+// NewExtractWriter creates a new extract writer in a directory,
+// creating it if needed.
+func NewExtractWriter(dir string) (*ExtractWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &ExtractWriter{
+		dir:   dir,
+		mutex: &sync.Mutex{},
+		files: make(map[string]*extractFile),
+	}, nil
+}
+
+// Write writes the values extracted by an extractor to its file,
+// skipping the values already written.
+func (w *ExtractWriter) Write(name string, values map[string]struct{}) {
+	if w == nil || len(values) == 0 {
+		return
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	file, err := w.getFile(name)
+	if err != nil {
+		gologger.Warningf("Could not open extract file for %s: %s\n", name, err)
+		return
+	}
+	for value := range values {
+		if _, ok := file.seen[value]; ok {
+			continue
+		}
+		file.seen[value] = struct{}{}
+		file.writer.WriteString(value)
+		file.writer.WriteRune('\n')
+	}
+}
+
+// getFile returns the file of an extractor, opening it on first use
+// and reading the values it already contains.
+func (w *ExtractWriter) getFile(name string) (*extractFile, error) {
+	if file, ok := w.files[name]; ok {
+		return file, nil
+	}
+
+	path := filepath.Join(w.dir, extractFileRegex.ReplaceAllString(name, "_")+".txt")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &extractFile{file: f, writer: bufio.NewWriter(f), seen: make(map[string]struct{})}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		file.seen[scanner.Text()] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "could not read extract file")
+	}
+	w.files[name] = file
+	return file, nil
+}
+
+// Close flushes and closes all the files of the writer.
+func (w *ExtractWriter) Close() {
+	if w == nil {
+		return
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for name, file := range w.files {
+		if err := file.writer.Flush(); err != nil {
+			gologger.Warningf("Could not write extract file for %s: %s\n", name, err)
+		}
+		file.file.Close()
+	}
+	w.files = make(map[string]*extractFile)
+}
@@ -0,0 +1,28 @@
+package executor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-extract-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "s3_buckets.txt"), []byte("existing\n"), 0644), "Could not write file")
+
+	writer, err := NewExtractWriter(dir)
+	require.Nil(t, err, "Could not create extract writer")
+	writer.Write("s3/buckets", map[string]struct{}{"existing": {}, "new": {}})
+	writer.Write("s3/buckets", map[string]struct{}{"new": {}})
+	writer.Close()
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "s3_buckets.txt"))
+	require.Nil(t, err, "Could not read extract file")
+	require.Equal(t, "existing\nnew\n", string(data), "Could not deduplicate extracted values")
+}
@@ -0,0 +1,198 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/pkg/requests"
+	"golang.org/x/net/http2"
+)
+
+// grpcTransport sends native grpc calls over http/2, using
+// cleartext http/2 for the http urls.
+type grpcTransport struct {
+	tls *http.Transport
+	h2c *h2cTransport
+}
+
+// RoundTrip sends a request using the transport matching its scheme
+func (t *grpcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" {
+		return t.h2c.RoundTrip(req)
+	}
+	return t.tls.RoundTrip(req)
+}
+
+// h2cTransport sends requests over cleartext http/2.
+//
+// Each request uses its own connection, dialed with the context of the
+// request, as the pooled connections of the http/2 transport are
+// dialed without one. The connection is closed with the response body.
+//
+// The connections are tunneled with CONNECT through the http proxy of
+// the transport, if any, since http/2 requests can't be forwarded.
+type h2cTransport struct {
+	transport   *http2.Transport
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	proxy       func(*http.Request) (*url.URL, error)
+}
+
+// RoundTrip sends a request on a new cleartext http/2 connection
+func (t *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	addr := req.URL.Host
+	if req.URL.Port() == "" {
+		addr = net.JoinHostPort(req.URL.Hostname(), "80")
+	}
+	conn, err := t.dial(req, addr)
+	if err != nil {
+		return nil, err
+	}
+	clientConn, err := t.transport.NewClientConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp, err := clientConn.RoundTrip(req)
+	if err != nil {
+		clientConn.Close()
+		return nil, err
+	}
+	resp.Body = &h2cBody{ReadCloser: resp.Body, conn: clientConn}
+	return resp, nil
+}
+
+// dial opens a connection to an address for a request, through the
+// proxy of the request if any.
+func (t *h2cTransport) dial(req *http.Request, addr string) (net.Conn, error) {
+	var proxyURL *url.URL
+	if t.proxy != nil {
+		var err error
+		if proxyURL, err = t.proxy(req); err != nil {
+			return nil, err
+		}
+	}
+	if proxyURL == nil {
+		return t.dialContext(req.Context(), "tcp", addr)
+	}
+	if proxyURL.Scheme != "http" {
+		return nil, fmt.Errorf("unsupported proxy scheme for cleartext http/2: %s", proxyURL.Scheme)
+	}
+
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "80")
+	}
+	conn, err := t.dialContext(req.Context(), "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := req.Context().Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var authorization string
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username()+":"+password))
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := sendConnect(conn, reader, addr, authorization)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused connection: %s", resp.Status)
+	}
+	if reader.Buffered() > 0 {
+		conn.Close()
+		return nil, fmt.Errorf("proxy sent unexpected data after connection")
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// h2cBody is the body of a response closing its connection once closed
+type h2cBody struct {
+	io.ReadCloser
+	conn *http2.ClientConn
+}
+
+// Close closes the body and its connection
+func (b *h2cBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.conn.Close()
+	return err
+}
+
+// makeGRPCTransport creates a transport sending requests over http/2
+// from the transport used for the other requests.
+func makeGRPCTransport(transport *http.Transport) http.RoundTripper {
+	transport.ForceAttemptHTTP2 = true
+
+	dialContext := transport.DialContext
+	if dialContext == nil {
+		dialContext = (&net.Dialer{}).DialContext
+	}
+	return &grpcTransport{
+		tls: transport,
+		h2c: &h2cTransport{
+			transport:   &http2.Transport{AllowHTTP: true},
+			dialContext: dialContext,
+			proxy:       transport.Proxy,
+		},
+	}
+}
+
+// addGRPCValues adds the status of a grpc response to the values of the
+// response as grpc_status and grpc_message, if the response has one.
+//
+// The status is read from the headers or trailers of native grpc
+// responses and from the trailer frame of grpc-web responses.
+func addGRPCValues(data map[string]interface{}, resp *http.Response, body string) {
+	header := resp.Header
+	if header.Get("Grpc-Status") == "" {
+		header = resp.Trailer
+	}
+	if header.Get("Grpc-Status") == "" {
+		header = getGRPCWebTrailer(body)
+	}
+
+	value := header.Get("Grpc-Status")
+	if value == "" {
+		return
+	}
+	status, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return
+	}
+	data["grpc_status"] = status
+	data["grpc_message"] = header.Get("Grpc-Message")
+}
+
+// getGRPCWebTrailer returns the trailers of a grpc-web response body
+func getGRPCWebTrailer(body string) http.Header {
+	for _, frame := range requests.ParseGRPCFrames([]byte(body)) {
+		if !frame.Trailer {
+			continue
+		}
+		reader := textproto.NewReader(bufio.NewReader(strings.NewReader(string(frame.Data) + "\r\n")))
+		header, err := reader.ReadMIMEHeader()
+		if err != nil && len(header) == 0 {
+			return nil
+		}
+		return http.Header(header)
+	}
+	return nil
+}
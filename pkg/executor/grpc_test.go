@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestGRPCTransportH2C(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Write([]byte(r.Proto))
+		w.Header().Set("Grpc-Status", "12")
+	})
+	server := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer server.Close()
+
+	dialed := 0
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed++
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}}
+	client := &http.Client{Transport: makeGRPCTransport(transport)}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/grpc.health.v1.Health/Check", strings.NewReader("test"))
+	require.Nil(t, err, "Could not create request")
+	resp, err := client.Do(req)
+	require.Nil(t, err, "Could not send request")
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Nil(t, err, "Could not read body")
+	require.Equal(t, "HTTP/2.0", string(body), "Could not send request over http/2")
+	require.Equal(t, "12", resp.Trailer.Get("Grpc-Status"), "Could not read trailers")
+	require.Equal(t, 1, dialed, "Could not dial with the transport")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err = http.NewRequest(http.MethodPost, server.URL+"/grpc.health.v1.Health/Check", nil)
+	require.Nil(t, err, "Could not create request")
+	_, err = client.Do(req.WithContext(ctx))
+	require.NotNil(t, err, "Could send request with a cancelled context")
+}
+
+// newTestConnectProxy starts a http proxy tunneling the CONNECT
+// requests authenticated with the basic credentials user:pass.
+func newTestConnectProxy(connects *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "only CONNECT is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		atomic.AddInt32(connects, 1)
+
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			target.Close()
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 OK\r\n\r\n"))
+		go func() {
+			io.Copy(target, conn)
+			target.Close()
+		}()
+		io.Copy(conn, target)
+		conn.Close()
+	}))
+}
+
+func TestGRPCTransportH2CProxy(t *testing.T) {
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}), &http2.Server{}))
+	defer server.Close()
+
+	var connects int32
+	proxy := newTestConnectProxy(&connects)
+	defer proxy.Close()
+
+	send := func(proxyURL string) (string, error) {
+		parsed, err := url.Parse(proxyURL)
+		require.Nil(t, err, "Could not parse proxy url")
+		transport := &http.Transport{DialContext: (&net.Dialer{}).DialContext, Proxy: http.ProxyURL(parsed)}
+		client := &http.Client{Transport: makeGRPCTransport(transport)}
+		resp, err := client.Post(server.URL+"/grpc.health.v1.Health/Check", "application/grpc", nil)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	body, err := send(strings.Replace(proxy.URL, "http://", "http://user:pass@", 1))
+	require.Nil(t, err, "Could not send request through proxy")
+	require.Equal(t, "HTTP/2.0", body, "Could not send request over http/2 through proxy")
+	require.Equal(t, int32(1), atomic.LoadInt32(&connects), "Could not tunnel request through proxy")
+
+	_, err = send(proxy.URL)
+	require.NotNil(t, err, "Could send request with proxy refusing connection")
+	_, err = send("socks5://127.0.0.1:1080")
+	require.NotNil(t, err, "Could send request through unsupported proxy")
+}
+
+func TestAddGRPCValues(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}, Trailer: http.Header{"Grpc-Status": []string{"5"}, "Grpc-Message": []string{"not found"}}}
+	data := make(map[string]interface{})
+	addGRPCValues(data, resp, "")
+	require.Equal(t, 5, data["grpc_status"], "Could not read status from trailers")
+	require.Equal(t, "not found", data["grpc_message"], "Could not read message from trailers")
+}
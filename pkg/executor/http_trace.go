@@ -0,0 +1,138 @@
+package executor
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+// httpTrace records the timings of the various phases of a http request
+// and the connection it was sent on.
+type httpTrace struct {
+	mutex *sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+	end          time.Time
+	// remoteAddr is the address of the connection the response was read from
+	remoteAddr string
+}
+
+// newHTTPTrace attaches a new timing trace to the request and returns it.
+func newHTTPTrace(req *retryablehttp.Request) *httpTrace {
+	t := &httpTrace{mutex: &sync.Mutex{}}
+
+	clientTrace := &httptrace.ClientTrace{
+		DNSStart: func(_ httptrace.DNSStartInfo) {
+			t.set(&t.dnsStart)
+		},
+		DNSDone: func(_ httptrace.DNSDoneInfo) {
+			t.set(&t.dnsDone)
+		},
+		ConnectStart: func(_, _ string) {
+			t.set(&t.connectStart)
+		},
+		ConnectDone: func(_, _ string, _ error) {
+			t.set(&t.connectDone)
+		},
+		TLSHandshakeStart: func() {
+			t.set(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, _ error) {
+			t.set(&t.tlsDone)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mutex.Lock()
+			t.remoteAddr = info.Conn.RemoteAddr().String()
+			t.mutex.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.set(&t.firstByte)
+		},
+	}
+	req.WithContext(httptrace.WithClientTrace(req.Context(), clientTrace))
+	return t
+}
+
+// set stores the current time in a field of the trace.
+func (t *httpTrace) set(field *time.Time) {
+	t.mutex.Lock()
+	*field = time.Now()
+	t.mutex.Unlock()
+}
+
+// Start marks the beginning of the request.
+func (t *httpTrace) Start() {
+	t.set(&t.start)
+}
+
+// Done marks the end of the request once the body has been read.
+func (t *httpTrace) Done() {
+	t.set(&t.end)
+}
+
+// toMap returns the timings of the request in seconds for use in
+// the dsl matchers. Phases that did not occur are reported as 0.
+func (t *httpTrace) toMap() map[string]interface{} {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return map[string]interface{}{
+		"dns_time":     elapsed(t.dnsStart, t.dnsDone),
+		"connect_time": elapsed(t.connectStart, t.connectDone),
+		"tls_time":     elapsed(t.tlsStart, t.tlsDone),
+		"ttfb":         elapsed(t.start, t.firstByte),
+		"duration":     elapsed(t.start, t.end),
+	}
+}
+
+// connectionInfo contains the details of the connection a response was read from.
+type connectionInfo struct {
+	IP         string
+	Port       string
+	TLSVersion string
+}
+
+// getConnectionInfo returns the details of the connection used for a response
+func (t *httpTrace) getConnectionInfo(resp *http.Response) *connectionInfo {
+	t.mutex.Lock()
+	remoteAddr := t.remoteAddr
+	t.mutex.Unlock()
+
+	info := &connectionInfo{}
+	if host, port, err := net.SplitHostPort(remoteAddr); err == nil {
+		info.IP, info.Port = host, port
+	}
+	if resp.TLS != nil {
+		info.TLSVersion = tlsVersions[resp.TLS.Version]
+	}
+	return info
+}
+
+// tlsVersions is a table for conversion of tls versions to string.
+var tlsVersions = map[uint16]string{
+	tls.VersionSSL30: "ssl3.0",
+	tls.VersionTLS10: "tls1.0",
+	tls.VersionTLS11: "tls1.1",
+	tls.VersionTLS12: "tls1.2",
+	tls.VersionTLS13: "tls1.3",
+}
+
+// elapsed returns the seconds elapsed between two points in time
+func elapsed(start, end time.Time) float64 {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start).Seconds()
+}
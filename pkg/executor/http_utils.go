@@ -2,8 +2,13 @@ package executor
 
 import (
 	"net/http"
+	"net/http/cookiejar"
 	"strings"
 	"unsafe"
+
+	"github.com/projectdiscovery/nuclei/pkg/matchers"
+	"golang.org/x/net/html"
+	"golang.org/x/net/publicsuffix"
 )
 
 // unsafeToString converts byte slice to string with zero allocations
@@ -29,3 +34,101 @@ func headersToString(headers http.Header) string {
 	}
 	return builder.String()
 }
+
+// addHTMLValues parses the html title and meta tags of a body into the
+// response values used by matchers and extractors.
+//
+// The title is stored as title while meta tags are stored as meta:<name>
+// using the lowercase name or property of the tag.
+func addHTMLValues(data map[string]interface{}, body string) {
+	data["title"] = ""
+
+	var inTitle, titleDone bool
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			return
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = tokenType == html.StartTagToken && !titleDone
+			case "meta":
+				var name, content string
+				for _, attr := range token.Attr {
+					switch attr.Key {
+					case "name", "property", "http-equiv":
+						if name == "" {
+							name = strings.ToLower(attr.Val)
+						}
+					case "content":
+						content = attr.Val
+					}
+				}
+				if name != "" {
+					data[matchers.MetaPartPrefix+name] = content
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				data["title"] = data["title"].(string) + string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			if inTitle {
+				if name, _ := tokenizer.TagName(); string(name) == "title" {
+					data["title"] = strings.TrimSpace(data["title"].(string))
+					inTitle, titleDone = false, true
+				}
+			}
+		}
+	}
+}
+
+// addRedirectCookies adds the cookies set by the responses of a redirect
+// chain to the next request of the chain, replacing the cookies of the
+// request with the same name.
+//
+// The cookies are stored in a jar following the chain so that only the
+// cookies whose domain, path and secure attributes match the url of the
+// request are sent, as a browser would.
+func addRedirectCookies(req *http.Request, via []*http.Request) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return
+	}
+
+	// The cookies of the request were already filtered for its host
+	var cookies []*http.Cookie
+	for _, cookie := range req.Cookies() {
+		cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value, Path: "/"})
+	}
+	jar.SetCookies(req.URL, cookies)
+
+	for _, redirect := range append(via[1:], req) {
+		if redirect.Response == nil || redirect.Response.Request == nil {
+			continue
+		}
+		jar.SetCookies(redirect.Response.Request.URL, redirect.Response.Cookies())
+	}
+
+	req.Header.Del("Cookie")
+	for _, cookie := range jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+}
+
+// getRedirectCookies returns the cookies set by the intermediate
+// responses of the redirect chain of a response, in order.
+func getRedirectCookies(resp *http.Response) string {
+	var cookies []string
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		var set []string
+		for _, cookie := range req.Response.Cookies() {
+			set = append(set, cookie.Name+"="+cookie.Value)
+		}
+		cookies = append(set, cookies...)
+	}
+	return strings.Join(cookies, "; ")
+}
@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectCookies(t *testing.T) {
+	var received string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Cookie")
+	}))
+	defer target.Close()
+
+	var location string
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/final" {
+			received = r.Header.Get("Cookie")
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret", Path: "/"})
+		http.SetCookie(w, &http.Cookie{Name: "admin", Value: "1", Path: "/admin"})
+		http.SetCookie(w, &http.Cookie{Name: "deleted", MaxAge: -1})
+		http.Redirect(w, r, location, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	client := &http.Client{CheckRedirect: makeCheckRedirectFunc(true, 10, true)}
+	send := func() *http.Response {
+		req, err := http.NewRequest(http.MethodGet, redirect.URL, nil)
+		require.Nil(t, err, "Could not create request")
+		req.AddCookie(&http.Cookie{Name: "deleted", Value: "value"})
+		req.AddCookie(&http.Cookie{Name: "initial", Value: "value"})
+		resp, err := client.Do(req)
+		require.Nil(t, err, "Could not send request")
+		resp.Body.Close()
+		return resp
+	}
+
+	location = redirect.URL + "/final"
+	resp := send()
+	require.Equal(t, "initial=value; session=secret", received, "Could not forward same host cookies")
+	require.Equal(t, "session=secret; admin=1; deleted=", getRedirectCookies(resp), "Could not get redirect cookies")
+
+	// The cookies of a different host are not forwarded, cookies not
+	// being port specific the other server is reached with its name.
+	location = strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+	send()
+	require.Equal(t, "", received, "Could forward cookies to another host")
+}
+
+func TestAddHTMLValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		values map[string]interface{}
+	}{
+		{"title", "<html><head><title>\n  Admin Login \n</title></head></html>", map[string]interface{}{"title": "Admin Login"}},
+		{"first title", "<title>First</title><svg><title>Second</title></svg>", map[string]interface{}{"title": "First"}},
+		{"missing title", "<html><body>no title</body></html>", map[string]interface{}{"title": ""}},
+		{"meta name", `<meta name="Generator" content="WordPress 5.4">`, map[string]interface{}{"title": "", "meta:generator": "WordPress 5.4"}},
+		{"meta property", `<meta property="og:title" content="Home"/>`, map[string]interface{}{"title": "", "meta:og:title": "Home"}},
+		{"meta http-equiv", `<meta http-equiv="Refresh" content="0; url=/login">`, map[string]interface{}{"title": "", "meta:refresh": "0; url=/login"}},
+		{"meta without name", `<meta charset="utf-8">`, map[string]interface{}{"title": ""}},
+	}
+	for _, test := range tests {
+		data := make(map[string]interface{})
+		addHTMLValues(data, test.body)
+		require.Equal(t, test.values, data, "Could not parse html values for %s", test.name)
+	}
+}
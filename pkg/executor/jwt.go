@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// jwtRegex matches the json web tokens in the headers and body of a response.
+var jwtRegex = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+// addJWTValues decodes the json web tokens found in the headers, including
+// the cookies, and the body of a response into the response values used by
+// matchers and extractors.
+//
+// The decoded tokens are stored as jwt, one compact json object with the
+// header and payload of a token per line. The algorithm and the exp and iat
+// claims of the first token are also stored as jwt_alg, jwt_exp and jwt_iat
+// for the dsl matchers using them, the claims defaulting to 0 when missing.
+func addJWTValues(data map[string]interface{}, header http.Header, body string) {
+	var decoded []string
+	seen := make(map[string]struct{})
+	for _, token := range jwtRegex.FindAllString(headersToString(header)+body, -1) {
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+
+		parts := strings.Split(token, ".")
+		tokenHeader, err := decodeJWTPart(parts[0])
+		if err != nil {
+			continue
+		}
+		tokenPayload, err := decodeJWTPart(parts[1])
+		if err != nil {
+			continue
+		}
+		value, err := json.Marshal(map[string]interface{}{"header": tokenHeader, "payload": tokenPayload})
+		if err != nil {
+			continue
+		}
+
+		if len(decoded) == 0 {
+			data["jwt_alg"], _ = tokenHeader["alg"].(string)
+			data["jwt_exp"] = getJWTClaim(tokenPayload, "exp")
+			data["jwt_iat"] = getJWTClaim(tokenPayload, "iat")
+		}
+		decoded = append(decoded, string(value))
+	}
+	data["jwt"] = strings.Join(decoded, "\n")
+}
+
+// decodeJWTPart decodes a base64url encoded json part of a json web token.
+func decodeJWTPart(part string) (map[string]interface{}, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// getJWTClaim returns a numeric claim of a token payload, or 0 if missing.
+func getJWTClaim(payload map[string]interface{}, name string) float64 {
+	value, _ := payload[name].(float64)
+	return value
+}
@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/pkg/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddJWTValues(t *testing.T) {
+	// {"alg":"none","typ":"JWT"}.{"sub":"admin","iat":1600000000}
+	token := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJzdWIiOiJhZG1pbiIsImlhdCI6MTYwMDAwMDAwMH0."
+	header := http.Header{"Set-Cookie": []string{"session=" + token + "; Path=/"}}
+
+	data := make(map[string]interface{})
+	addJWTValues(data, header, `{"token":"`+token+`","other":"eyJub3Q.eyJhIHRva2Vu"}`)
+	require.Equal(t, `{"header":{"alg":"none","typ":"JWT"},"payload":{"iat":1600000000,"sub":"admin"}}`, data["jwt"], "Could not decode token")
+	require.Equal(t, "none", data["jwt_alg"], "Could not get algorithm")
+	require.Equal(t, float64(1600000000), data["jwt_iat"], "Could not get iat claim")
+	require.Equal(t, float64(0), data["jwt_exp"], "Could not default missing exp claim")
+
+	data = make(map[string]interface{})
+	addJWTValues(data, http.Header{}, "no tokens")
+	require.Equal(t, "", data["jwt"], "Could not handle response without tokens")
+	require.NotContains(t, data, "jwt_alg", "Could not skip dsl values without tokens")
+}
+
+func TestExecuteHTTPJWTDSL(t *testing.T) {
+	// {"alg":"none","typ":"JWT"}.{"sub":"admin","iat":1600000000}
+	token := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJzdWIiOiJhZG1pbiIsImlhdCI6MTYwMDAwMDAwMH0."
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: token})
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "nuclei-jwt-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jwt.yaml")
+	require.Nil(t, ioutil.WriteFile(path, []byte(`id: jwt-none
+info:
+  name: jwt none
+  author: test
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: dsl
+        dsl:
+          - 'jwt_alg == "none" && jwt_iat > 0'
+`), 0644), "Could not write template")
+	template, err := templates.ParseTemplate(path)
+	require.Nil(t, err, "Could not parse template")
+
+	var results []*Result
+	output := NewOutputWriter(&OutputOptions{Callbacks: []ResultCallback{func(result *Result) {
+		results = append(results, result)
+	}}})
+	executor, err := NewHTTPExecutor(&HTTPOptions{Template: template, HTTPRequest: template.RequestsHTTP[0], Output: output, Timeout: 5})
+	require.Nil(t, err, "Could not create executor")
+	require.Nil(t, executor.ExecuteHTTP(server.URL), "Could not execute request")
+	output.Close()
+	require.Len(t, results, 1, "Could not match jwt values with dsl matcher")
+}
@@ -0,0 +1,220 @@
+package executor
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// ntlmSignature is the signature starting all the ntlm messages
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// ntlmNegotiateFlags are the flags of the negotiate message, asking for
+// unicode strings and ntlmv2 with extended session security.
+const ntlmNegotiateFlags = 0x00000001 | // NEGOTIATE_UNICODE
+	0x00000004 | // REQUEST_TARGET
+	0x00000200 | // NEGOTIATE_NTLM
+	0x00008000 | // NEGOTIATE_ALWAYS_SIGN
+	0x00080000 | // NEGOTIATE_EXTENDED_SESSIONSECURITY
+	0x20000000 | // NEGOTIATE_128
+	0x80000000 // NEGOTIATE_56
+
+// ntlmAvTimestamp is the id of the timestamp in the target info of a challenge
+const ntlmAvTimestamp = 7
+
+// ntlmNegotiate returns the negotiate message starting an ntlm authentication
+func ntlmNegotiate() []byte {
+	message := make([]byte, 32)
+	copy(message, ntlmSignature)
+	binary.LittleEndian.PutUint32(message[8:], 1)
+	binary.LittleEndian.PutUint32(message[12:], ntlmNegotiateFlags)
+	return message
+}
+
+// ntlmChallenge is a challenge message sent by the server
+type ntlmChallenge struct {
+	flags           uint32
+	serverChallenge []byte
+	targetInfo      []byte
+}
+
+// parseNTLMChallenge parses a challenge message
+func parseNTLMChallenge(data []byte) (*ntlmChallenge, error) {
+	if len(data) < 32 || !bytes.Equal(data[:8], ntlmSignature) || binary.LittleEndian.Uint32(data[8:]) != 2 {
+		return nil, errors.New("invalid ntlm challenge message")
+	}
+
+	challenge := &ntlmChallenge{
+		flags:           binary.LittleEndian.Uint32(data[20:]),
+		serverChallenge: data[24:32],
+	}
+	if len(data) >= 48 {
+		length := int(binary.LittleEndian.Uint16(data[40:]))
+		offset := int(binary.LittleEndian.Uint32(data[44:]))
+		if offset+length > len(data) {
+			return nil, errors.New("invalid ntlm target info")
+		}
+		challenge.targetInfo = data[offset : offset+length]
+	}
+	return challenge, nil
+}
+
+// getTimestamp returns the timestamp of the target info of the challenge, if any
+func (c *ntlmChallenge) getTimestamp() ([]byte, bool) {
+	info := c.targetInfo
+	for len(info) >= 4 {
+		id := binary.LittleEndian.Uint16(info)
+		length := int(binary.LittleEndian.Uint16(info[2:]))
+		if len(info) < 4+length {
+			break
+		}
+		if id == ntlmAvTimestamp && length == 8 {
+			return info[4:12], true
+		}
+		info = info[4+length:]
+	}
+	return nil, false
+}
+
+// ntlmAuthenticate returns the authenticate message answering a challenge
+// using ntlmv2 responses.
+func ntlmAuthenticate(challenge *ntlmChallenge, domain, user, password string) ([]byte, error) {
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, err
+	}
+
+	// Use the timestamp of the server if it sent one, in which
+	// case the lm response must be empty.
+	timestamp, fromServer := challenge.getTimestamp()
+	if !fromServer {
+		timestamp = make([]byte, 8)
+		// Windows file times count 100 nanoseconds intervals since 1601
+		binary.LittleEndian.PutUint64(timestamp, uint64(time.Now().UnixNano()/100+116444736000000000))
+	}
+
+	ntResponse, lmResponse := ntlmV2Responses(challenge, ntlmV2Hash(domain, user, password), clientChallenge, timestamp)
+	if fromServer {
+		lmResponse = make([]byte, 24)
+	}
+
+	payloads := [][]byte{lmResponse, ntResponse, ntlmString(domain), ntlmString(user), nil, nil}
+	message := make([]byte, 64)
+	copy(message, ntlmSignature)
+	binary.LittleEndian.PutUint32(message[8:], 3)
+
+	// Write the security buffers pointing to the payloads
+	offset := len(message)
+	for i, payload := range payloads {
+		field := message[12+i*8:]
+		binary.LittleEndian.PutUint16(field, uint16(len(payload)))
+		binary.LittleEndian.PutUint16(field[2:], uint16(len(payload)))
+		binary.LittleEndian.PutUint32(field[4:], uint32(offset))
+		offset += len(payload)
+	}
+	binary.LittleEndian.PutUint32(message[60:], challenge.flags&ntlmNegotiateFlags)
+
+	for _, payload := range payloads {
+		message = append(message, payload...)
+	}
+	return message, nil
+}
+
+// ntlmV2Hash returns the ntlmv2 hash of the credentials of a user
+func ntlmV2Hash(domain, user, password string) []byte {
+	ntHash := md4Sum(ntlmString(password))
+	return hmacMD5(ntHash[:], ntlmString(strings.ToUpper(user)+domain))
+}
+
+// ntlmV2Responses returns the nt and lm responses to a challenge
+func ntlmV2Responses(challenge *ntlmChallenge, hash, clientChallenge, timestamp []byte) (nt []byte, lm []byte) {
+	blob := []byte{0x01, 0x01, 0, 0, 0, 0, 0, 0}
+	blob = append(blob, timestamp...)
+	blob = append(blob, clientChallenge...)
+	blob = append(blob, 0, 0, 0, 0)
+	blob = append(blob, challenge.targetInfo...)
+	blob = append(blob, 0, 0, 0, 0)
+
+	ntProof := hmacMD5(hash, append(append([]byte{}, challenge.serverChallenge...), blob...))
+	nt = append(ntProof, blob...)
+
+	lm = hmacMD5(hash, append(append([]byte{}, challenge.serverChallenge...), clientChallenge...))
+	lm = append(lm, clientChallenge...)
+	return nt, lm
+}
+
+// ntlmString encodes a string as utf-16 little endian
+func ntlmString(value string) []byte {
+	encoded := utf16.Encode([]rune(value))
+	data := make([]byte, len(encoded)*2)
+	for i, char := range encoded {
+		binary.LittleEndian.PutUint16(data[i*2:], char)
+	}
+	return data
+}
+
+// hmacMD5 returns the hmac-md5 of data
+func hmacMD5(key, data []byte) []byte {
+	mac := hmac.New(md5.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// md4Sum returns the md4 checksum of data, which is used by ntlm
+// to hash the passwords.
+func md4Sum(data []byte) [16]byte {
+	a, b, c, d := uint32(0x67452301), uint32(0xefcdab89), uint32(0x98badcfe), uint32(0x10325476)
+
+	// Pad the message to a multiple of 64 bytes ending with its length in bits
+	padded := append(append([]byte{}, data...), 0x80)
+	for len(padded)%64 != 56 {
+		padded = append(padded, 0)
+	}
+	length := make([]byte, 8)
+	binary.LittleEndian.PutUint64(length, uint64(len(data))*8)
+	padded = append(padded, length...)
+
+	rotate := bits.RotateLeft32
+	for i := 0; i < len(padded); i += 64 {
+		var x [16]uint32
+		for j := range x {
+			x[j] = binary.LittleEndian.Uint32(padded[i+j*4:])
+		}
+		aa, bb, cc, dd := a, b, c, d
+
+		for _, j := range []int{0, 4, 8, 12} {
+			a = rotate(a+((b&c)|(^b&d))+x[j], 3)
+			d = rotate(d+((a&b)|(^a&c))+x[j+1], 7)
+			c = rotate(c+((d&a)|(^d&b))+x[j+2], 11)
+			b = rotate(b+((c&d)|(^c&a))+x[j+3], 19)
+		}
+		for _, j := range []int{0, 1, 2, 3} {
+			a = rotate(a+((b&c)|(b&d)|(c&d))+x[j]+0x5a827999, 3)
+			d = rotate(d+((a&b)|(a&c)|(b&c))+x[j+4]+0x5a827999, 5)
+			c = rotate(c+((d&a)|(d&b)|(a&b))+x[j+8]+0x5a827999, 9)
+			b = rotate(b+((c&d)|(c&a)|(d&a))+x[j+12]+0x5a827999, 13)
+		}
+		for _, j := range []int{0, 2, 1, 3} {
+			a = rotate(a+(b^c^d)+x[j]+0x6ed9eba1, 3)
+			d = rotate(d+(a^b^c)+x[j+8]+0x6ed9eba1, 9)
+			c = rotate(c+(d^a^b)+x[j+4]+0x6ed9eba1, 11)
+			b = rotate(b+(c^d^a)+x[j+12]+0x6ed9eba1, 15)
+		}
+
+		a, b, c, d = a+aa, b+bb, c+cc, d+dd
+	}
+
+	var sum [16]byte
+	binary.LittleEndian.PutUint32(sum[0:], a)
+	binary.LittleEndian.PutUint32(sum[4:], b)
+	binary.LittleEndian.PutUint32(sum[8:], c)
+	binary.LittleEndian.PutUint32(sum[12:], d)
+	return sum
+}
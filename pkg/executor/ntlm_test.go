@@ -0,0 +1,34 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMD4(t *testing.T) {
+	sum := md4Sum(nil)
+	require.Equal(t, "31d6cfe0d16ae931b73c59d7e0c089c0", hex.EncodeToString(sum[:]), "Could not hash empty data")
+	sum = md4Sum([]byte("abc"))
+	require.Equal(t, "a448017aaf21d8525fc10ae87aa6729d", hex.EncodeToString(sum[:]), "Could not hash data")
+	sum = md4Sum([]byte("12345678901234567890123456789012345678901234567890123456789012345678901234567890"))
+	require.Equal(t, "e33b4ddc9c38f2199c3e7b164fcc0536", hex.EncodeToString(sum[:]), "Could not hash multiple blocks")
+}
+
+// TestNTLMV2Responses uses the ntlmv2 authentication example of MS-NLMP 4.2.4
+func TestNTLMV2Responses(t *testing.T) {
+	targetInfo, _ := hex.DecodeString("02000c0044006f006d00610069006e0001000c005300650072007600650072000000000000000000")
+	challenge := &ntlmChallenge{
+		serverChallenge: []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+		targetInfo:      targetInfo[:36],
+	}
+
+	hash := ntlmV2Hash("Domain", "User", "Password")
+	require.Equal(t, "0c868a403bfd7a93a3001ef22ef02e3f", hex.EncodeToString(hash), "Could not compute ntlmv2 hash")
+
+	nt, lm := ntlmV2Responses(challenge, hash, bytes.Repeat([]byte{0xaa}, 8), make([]byte, 8))
+	require.Equal(t, "68cd0ab851e51c96aabc927bebef6a1c", hex.EncodeToString(nt[:16]), "Could not compute nt proof")
+	require.Equal(t, "86c35097ac9cec102554764a57cccc19aaaaaaaaaaaaaaaa", hex.EncodeToString(lm), "Could not compute lm response")
+}
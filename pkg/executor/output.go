@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"encoding/json"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/pkg/extractors"
+	"github.com/projectdiscovery/nuclei/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/pkg/templates"
+)
+
+// Result is a result of a template written in the json output.
+type Result struct {
+	// Template is the id of the template that matched
+	Template string `json:"template"`
+	// TemplateVersion is the version of the template, if any
+	TemplateVersion string `json:"template_version,omitempty"`
+	// TemplatePath is the file the template was parsed from
+	TemplatePath string `json:"template_path,omitempty"`
+	// TemplateCommit is the git commit of the template file, if any
+	TemplateCommit string `json:"template_commit,omitempty"`
+	// Severity is the severity of the template
+	Severity string `json:"severity,omitempty"`
+	// Type is the type of the request, http or dns
+	Type string `json:"type"`
+	// Matched is the URL or domain that matched
+	Matched string `json:"matched"`
+	// MatcherNames contains the names of the matchers that matched, if any
+	MatcherNames []string `json:"matcher_names,omitempty"`
+	// ExtractedResults contains the extracted values, if any
+	ExtractedResults []string `json:"extracted_results,omitempty"`
+	// IP is the remote ip address the response was received from, if any
+	IP string `json:"ip,omitempty"`
+	// Port is the remote port the response was received from, if any
+	Port string `json:"port,omitempty"`
+	// TLSVersion is the tls version used for the response, if any
+	TLSVersion string `json:"tls_version,omitempty"`
+	// CorrelationID is the id of the blind xss payload injected, if any
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// IsFinding returns true if the result is a finding of a template, and
+// not a record of the blind xss correlation log.
+func (r *Result) IsFinding() bool {
+	return r.Type != correlationResultType
+}
+
+// ResultCallback is called with every result found by an executor.
+type ResultCallback func(result *Result)
+
+// newResult creates a result of a template for the matched URL or domain
+func newResult(template *templates.Template, requestType, matched string, matcherNames, extractorResults []string) *Result {
+	return &Result{
+		Template:         template.ID,
+		TemplateVersion:  template.Version,
+		TemplatePath:     template.Path,
+		TemplateCommit:   template.Commit,
+		Severity:         template.Info.Severity,
+		Type:             requestType,
+		Matched:          matched,
+		MatcherNames:     matcherNames,
+		ExtractedResults: extractorResults,
+	}
+}
+
+// appendMatcherName appends the name of a matcher to the names
+// of the matched matchers, if it has one.
+func appendMatcherName(names []string, matcher *matchers.Matcher) []string {
+	if matcher.Name == "" {
+		return names
+	}
+	return append(names, matcher.Name)
+}
+
+// getExtractorName returns the name of an extractor, defaulting
+// to the id of its template.
+func getExtractorName(template *templates.Template, extractor *extractors.Extractor) string {
+	if extractor.Name != "" {
+		return extractor.Name
+	}
+	return template.ID
+}
+
+// formatOutputJSON formats a result as a json line
+func formatOutputJSON(result *Result) []byte {
+	data, err := json.Marshal(result)
+	if err != nil {
+		gologger.Warningf("Could not marshal result: %s\n", err)
+		return nil
+	}
+	return append(data, '\n')
+}
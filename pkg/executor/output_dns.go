@@ -2,19 +2,22 @@ package executor
 
 import (
 	"strings"
-
-	"github.com/projectdiscovery/gologger"
-	"github.com/projectdiscovery/nuclei/pkg/matchers"
 )
 
 // writeOutputDNS writes dns output to streams
-func (e *DNSExecutor) writeOutputDNS(domain string, matcher *matchers.Matcher, extractorResults []string) {
+func (e *DNSExecutor) writeOutputDNS(domain string, matcherNames []string, extractorResults []string) {
+	result := newResult(e.template, "dns", domain, matcherNames, extractorResults)
+	if e.jsonOutput {
+		e.output.Write(result, formatOutputJSON(result))
+		return
+	}
+
 	builder := &strings.Builder{}
 	builder.WriteRune('[')
 	builder.WriteString(e.template.ID)
-	if matcher != nil && len(matcher.Name) > 0 {
+	if len(matcherNames) > 0 {
 		builder.WriteString(":")
-		builder.WriteString(matcher.Name)
+		builder.WriteString(strings.Join(matcherNames, ","))
 	}
 	builder.WriteString("] [dns] ")
 
@@ -33,13 +36,5 @@ func (e *DNSExecutor) writeOutputDNS(domain string, matcher *matchers.Matcher, e
 	}
 	builder.WriteRune('\n')
 
-	// Write output to screen as well as any output file
-	message := builder.String()
-	gologger.Silentf("%s", message)
-
-	if e.writer != nil {
-		e.outputMutex.Lock()
-		e.writer.WriteString(message)
-		e.outputMutex.Unlock()
-	}
+	e.output.Write(result, []byte(builder.String()))
 }
@@ -3,27 +3,29 @@ package executor
 import (
 	"strings"
 
-	"github.com/projectdiscovery/gologger"
-	"github.com/projectdiscovery/nuclei/pkg/matchers"
 	"github.com/projectdiscovery/retryablehttp-go"
 )
 
 // writeOutputHTTP writes http output to streams
-func (e *HTTPExecutor) writeOutputHTTP(req *retryablehttp.Request, matcher *matchers.Matcher, extractorResults []string) {
+func (e *HTTPExecutor) writeOutputHTTP(req *retryablehttp.Request, conn *connectionInfo, matcherNames []string, extractorResults []string) {
+	result := newResult(e.template, "http", req.URL.String(), matcherNames, extractorResults)
+	result.IP, result.Port, result.TLSVersion = conn.IP, conn.Port, conn.TLSVersion
+	if e.jsonOutput {
+		e.output.Write(result, formatOutputJSON(result))
+		return
+	}
+
 	builder := &strings.Builder{}
 
 	builder.WriteRune('[')
 	builder.WriteString(e.template.ID)
-	if matcher != nil && len(matcher.Name) > 0 {
+	if len(matcherNames) > 0 {
 		builder.WriteString(":")
-		builder.WriteString(matcher.Name)
+		builder.WriteString(strings.Join(matcherNames, ","))
 	}
 	builder.WriteString("] [http] ")
 
-	// Escape the URL by replacing all % with %%
-	URL := req.URL.String()
-	escapedURL := strings.Replace(URL, "%", "%%", -1)
-	builder.WriteString(escapedURL)
+	builder.WriteString(req.URL.String())
 
 	// If any extractors, write the results
 	if len(extractorResults) > 0 {
@@ -38,13 +40,5 @@ func (e *HTTPExecutor) writeOutputHTTP(req *retryablehttp.Request, matcher *matc
 	}
 	builder.WriteRune('\n')
 
-	// Write output to screen as well as any output file
-	message := builder.String()
-	gologger.Silentf("%s", message)
-
-	if e.writer != nil {
-		e.outputMutex.Lock()
-		e.writer.WriteString(message)
-		e.outputMutex.Unlock()
-	}
+	e.output.Write(result, []byte(builder.String()))
 }
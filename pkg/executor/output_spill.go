@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// spillQueue is a disk-backed queue of the results which didn't fit
+// in the buffer of the output writer, stored as length-prefixed json
+// records in a temporary file.
+//
+// The file is truncated every time the queue is emptied so that it
+// only grows as much as the output falls behind.
+type spillQueue struct {
+	mutex  *sync.Mutex
+	file   *os.File
+	read   int64
+	write  int64
+	length int
+}
+
+// spillRecord is a queued result along with its formatted output
+type spillRecord struct {
+	Result *Result `json:"result"`
+	Data   []byte  `json:"data"`
+}
+
+// newSpillQueue creates a new spill queue in a temporary file.
+func newSpillQueue() (*spillQueue, error) {
+	file, err := ioutil.TempFile("", "nuclei-output-*")
+	if err != nil {
+		return nil, err
+	}
+	return &spillQueue{mutex: &sync.Mutex{}, file: file}, nil
+}
+
+// push appends an item to the queue.
+func (q *spillQueue) push(item *outputItem) error {
+	record, err := json.Marshal(&spillRecord{Result: item.result, Data: item.data})
+	if err != nil {
+		return err
+	}
+	data := make([]byte, 4+len(record))
+	binary.BigEndian.PutUint32(data, uint32(len(record)))
+	copy(data[4:], record)
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if _, err := q.file.WriteAt(data, q.write); err != nil {
+		return err
+	}
+	q.write += int64(len(data))
+	q.length++
+	return nil
+}
+
+// pop removes the first item of the queue, returning nil if it is empty.
+//
+// The whole queue is dropped if an item can't be read, as the following
+// items can't be located reliably anymore. The number of items dropped
+// is returned along with the error.
+func (q *spillQueue) pop() (*outputItem, int, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.length == 0 {
+		return nil, 0, nil
+	}
+
+	item, err := q.readItem()
+	if err != nil {
+		dropped := q.length
+		q.reset()
+		return nil, dropped, err
+	}
+	q.length--
+
+	// Reclaim the disk space once the queue is empty
+	if q.length == 0 {
+		if err := q.reset(); err != nil {
+			return nil, 0, err
+		}
+	}
+	return item, 0, nil
+}
+
+// readItem reads the item at the read offset of the queue, moving the
+// offset past it.
+func (q *spillQueue) readItem() (*outputItem, error) {
+	header := make([]byte, 4)
+	if _, err := q.file.ReadAt(header, q.read); err != nil {
+		return nil, err
+	}
+	data := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := q.file.ReadAt(data, q.read+4); err != nil {
+		return nil, err
+	}
+	q.read += int64(4 + len(data))
+
+	record := &spillRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, err
+	}
+	return &outputItem{result: record.Result, data: record.Data}, nil
+}
+
+// reset empties the queue, truncating its file.
+func (q *spillQueue) reset() error {
+	q.read, q.write, q.length = 0, 0, 0
+	return q.file.Truncate(0)
+}
+
+// len returns the number of items in the queue.
+func (q *spillQueue) len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.length
+}
+
+// close closes and removes the file of the queue.
+func (q *spillQueue) close() {
+	q.file.Close()
+	os.Remove(q.file.Name())
+}
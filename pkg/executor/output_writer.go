@@ -0,0 +1,180 @@
+package executor
+
+import (
+	"bufio"
+	"io"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// outputBufferSize is the number of results buffered before
+// the executors block on writing the output.
+const outputBufferSize = 1024
+
+// OutputWriter writes the results of the executors to multiple sinks
+// from a single goroutine, so that every result is written atomically
+// and in the order it was found.
+//
+// When spilling is enabled, the results which don't fit in the buffer
+// are queued on disk instead of blocking the executors, and written
+// once the buffer is empty. The following results are queued on disk
+// as well until the queue is emptied, to keep them in order.
+type OutputWriter struct {
+	items     chan *outputItem
+	sinks     []*bufio.Writer
+	callbacks []ResultCallback
+	spill     *spillQueue
+	// spillMutex orders the results queued in the buffer and on disk
+	spillMutex *sync.Mutex
+	done       chan struct{}
+}
+
+// OutputOptions contains the sinks the results are written to.
+type OutputOptions struct {
+	// Sinks are the writers the formatted results are written to.
+	Sinks []io.Writer
+	// Callbacks are called with every result written, in order.
+	Callbacks []ResultCallback
+	// Spill queues the results on disk when the buffer is full
+	// instead of blocking the executors.
+	Spill bool
+}
+
+// outputItem is a result along with its formatted output
+type outputItem struct {
+	result *Result
+	data   []byte
+}
+
+// NewOutputWriter creates a new output writer and starts writing
+// the results to the sinks until it is closed.
+func NewOutputWriter(options *OutputOptions) *OutputWriter {
+	w := &OutputWriter{
+		items:      make(chan *outputItem, outputBufferSize),
+		callbacks:  options.Callbacks,
+		spillMutex: &sync.Mutex{},
+		done:       make(chan struct{}),
+	}
+	for _, sink := range options.Sinks {
+		w.sinks = append(w.sinks, bufio.NewWriter(sink))
+	}
+	if options.Spill {
+		spill, err := newSpillQueue()
+		if err != nil {
+			gologger.Warningf("Could not create output spill file: %s\n", err)
+		}
+		w.spill = spill
+	}
+
+	go w.run()
+	return w
+}
+
+// run writes the results to the sinks, flushing them once
+// no more results are waiting to be written.
+func (w *OutputWriter) run() {
+	defer close(w.done)
+
+	for item := range w.items {
+		// Nil items only wake up the writer to read the spilled results
+		if item != nil {
+			w.writeItem(item)
+		}
+		if len(w.items) == 0 {
+			w.writeSpilled()
+			w.flush()
+		}
+	}
+	w.writeSpilled()
+	w.flush()
+
+	if w.spill != nil {
+		w.spill.close()
+	}
+}
+
+// writeItem writes a result to the callbacks and the sinks
+func (w *OutputWriter) writeItem(item *outputItem) {
+	for _, callback := range w.callbacks {
+		callback(item.result)
+	}
+	for _, sink := range w.sinks {
+		if _, err := sink.Write(item.data); err != nil {
+			gologger.Warningf("Could not write output: %s\n", err)
+		}
+	}
+}
+
+// writeSpilled writes the results queued on disk, if any
+func (w *OutputWriter) writeSpilled() {
+	if w.spill == nil {
+		return
+	}
+	for {
+		item, dropped, err := w.spill.pop()
+		if err != nil {
+			gologger.Warningf("Could not read spilled output, dropping %d results: %s\n", dropped, err)
+			return
+		}
+		if item == nil {
+			return
+		}
+		w.writeItem(item)
+	}
+}
+
+// flush flushes all the sinks
+func (w *OutputWriter) flush() {
+	for _, sink := range w.sinks {
+		if err := sink.Flush(); err != nil {
+			gologger.Warningf("Could not write output: %s\n", err)
+		}
+	}
+}
+
+// Write queues a result along with its formatted output for writing.
+//
+// A nil writer discards the results.
+func (w *OutputWriter) Write(result *Result, data []byte) {
+	if w == nil {
+		return
+	}
+
+	item := &outputItem{result: result, data: data}
+	if w.spill == nil {
+		w.items <- item
+		return
+	}
+
+	w.spillMutex.Lock()
+	defer w.spillMutex.Unlock()
+
+	// Only use the buffer while no results are queued on disk, as
+	// they would be written before the results queued earlier
+	if w.spill.len() == 0 {
+		select {
+		case w.items <- item:
+			return
+		default:
+		}
+	}
+	if err := w.spill.push(item); err != nil {
+		gologger.Warningf("Could not spill output: %s\n", err)
+		w.items <- item
+		return
+	}
+	// Wake up the writer in case it emptied the buffer meanwhile
+	select {
+	case w.items <- nil:
+	default:
+	}
+}
+
+// Close writes the remaining results and stops the writer.
+//
+// The writer must not be used after it is closed.
+func (w *OutputWriter) Close() {
+	close(w.items)
+	<-w.done
+}
@@ -0,0 +1,83 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputWriterSpill(t *testing.T) {
+	// The writer blocks on the first result, then on the second one
+	// once the buffer has room again
+	release, reached, resume := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	var templates []string
+	buffer := &bytes.Buffer{}
+	writer := NewOutputWriter(&OutputOptions{
+		Sinks: []io.Writer{buffer},
+		Callbacks: []ResultCallback{func(result *Result) {
+			switch result.Template {
+			case "0":
+				<-release
+			case "1":
+				close(reached)
+				<-resume
+			}
+			templates = append(templates, result.Template)
+		}},
+		Spill: true,
+	})
+	require.NotNil(t, writer.spill, "Could not create spill queue")
+
+	// Writing more results than buffered spills them instead of blocking
+	count := outputBufferSize * 2
+	var expected []string
+	write := func(i int) {
+		writer.Write(&Result{Template: strconv.Itoa(i)}, []byte(strconv.Itoa(i)+"\n"))
+		expected = append(expected, strconv.Itoa(i))
+	}
+	for i := 0; i < count; i++ {
+		write(i)
+	}
+	require.Greater(t, writer.spill.len(), 0, "Could not spill results")
+
+	// The results written once the buffer has room are written after
+	// the spilled ones
+	close(release)
+	<-reached
+	write(count)
+	close(resume)
+	writer.Close()
+	require.Equal(t, expected, templates, "Could not write spilled results in order")
+	require.Equal(t, expected, strings.Fields(buffer.String()), "Could not write spilled output in order")
+}
+
+func TestSpillQueueDropCorrupted(t *testing.T) {
+	queue, err := newSpillQueue()
+	require.Nil(t, err, "Could not create spill queue")
+	defer queue.close()
+
+	for i := 0; i < 3; i++ {
+		require.Nil(t, queue.push(&outputItem{result: &Result{Template: strconv.Itoa(i)}}), "Could not push item")
+	}
+	_, err = queue.file.WriteAt([]byte("corrupted"), 4)
+	require.Nil(t, err, "Could not corrupt spill file")
+
+	item, dropped, err := queue.pop()
+	require.NotNil(t, err, "Could not detect corrupted item")
+	require.Nil(t, item, "Got corrupted item")
+	require.Equal(t, 3, dropped, "Could not drop the queue")
+	require.Equal(t, 0, queue.len(), "Could not empty the queue")
+
+	// The queue is usable again once dropped
+	item, _, err = queue.pop()
+	require.Nil(t, err, "Could not pop empty queue")
+	require.Nil(t, item, "Got item from empty queue")
+	require.Nil(t, queue.push(&outputItem{result: &Result{Template: "new"}}), "Could not push item")
+	item, _, err = queue.pop()
+	require.Nil(t, err, "Could not pop item")
+	require.Equal(t, "new", item.result.Template, "Could not get pushed item")
+}
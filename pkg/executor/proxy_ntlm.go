@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ntlmProxyDialer tunnels the connections through a http proxy
+// requiring ntlm authentication using the CONNECT method.
+//
+// Since ntlm authenticates a connection rather than a request, the
+// connections to both http and https hosts are tunneled.
+type ntlmProxyDialer struct {
+	proxyAddr string
+	domain    string
+	user      string
+	password  string
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// newNTLMProxyDialer creates a dialer for a proxy url containing the
+// credentials of the user, written as DOMAIN\user or user@DOMAIN.
+func newNTLMProxyDialer(proxyURL *url.URL, dial func(ctx context.Context, network, addr string) (net.Conn, error)) *ntlmProxyDialer {
+	dialer := &ntlmProxyDialer{proxyAddr: proxyURL.Host, dial: dial}
+	if proxyURL.Port() == "" {
+		dialer.proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "8080")
+	}
+	if proxyURL.User != nil {
+		dialer.user = proxyURL.User.Username()
+		dialer.password, _ = proxyURL.User.Password()
+	}
+	if parts := strings.SplitN(dialer.user, `\`, 2); len(parts) == 2 {
+		dialer.domain, dialer.user = parts[0], parts[1]
+	} else if parts := strings.SplitN(dialer.user, "@", 2); len(parts) == 2 {
+		dialer.user, dialer.domain = parts[0], parts[1]
+	}
+	return dialer
+}
+
+// DialContext opens an authenticated tunnel to an address through the proxy
+func (d *ntlmProxyDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.dial(ctx, "tcp", d.proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if err := d.connect(conn, addr); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// connect runs the ntlm handshake on a connection to the proxy
+func (d *ntlmProxyDialer) connect(conn net.Conn, addr string) error {
+	reader := bufio.NewReader(conn)
+
+	resp, err := sendConnect(conn, reader, addr, "NTLM "+base64.StdEncoding.EncodeToString(ntlmNegotiate()))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		var challenge *ntlmChallenge
+		for _, value := range resp.Header.Values("Proxy-Authenticate") {
+			if !strings.HasPrefix(value, "NTLM ") {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(strings.TrimPrefix(value, "NTLM ")))
+			if err != nil {
+				return fmt.Errorf("could not decode ntlm challenge: %s", err)
+			}
+			if challenge, err = parseNTLMChallenge(data); err != nil {
+				return err
+			}
+		}
+		if challenge == nil {
+			return fmt.Errorf("proxy did not send an ntlm challenge")
+		}
+
+		authenticate, err := ntlmAuthenticate(challenge, d.domain, d.user, d.password)
+		if err != nil {
+			return err
+		}
+		resp, err = sendConnect(conn, reader, addr, "NTLM "+base64.StdEncoding.EncodeToString(authenticate))
+		if err != nil {
+			return err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy refused connection: %s", resp.Status)
+	}
+	if reader.Buffered() > 0 {
+		return fmt.Errorf("proxy sent unexpected data after connection")
+	}
+	return nil
+}
+
+// sendConnect sends a CONNECT request with an authorization, if any,
+// to the proxy and reads its response.
+func sendConnect(conn net.Conn, reader *bufio.Reader, addr, authorization string) (*http.Response, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if authorization != "" {
+		req.Header.Set("Proxy-Authorization", authorization)
+	}
+	req.Header.Set("Proxy-Connection", "Keep-Alive")
+	req.Header.Set("User-Agent", "Nuclei (@pdiscoveryio)")
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, err
+	}
+	// The tunnel starts right after an accepted request, otherwise read
+	// the body of the response so that the connection can be reused.
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	return resp, nil
+}
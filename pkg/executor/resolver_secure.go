@@ -0,0 +1,307 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// maxResolverCacheTTL is the maximum duration the addresses of a
+// host are cached for, whatever the ttl of their records.
+const maxResolverCacheTTL = 5 * time.Minute
+
+// SecureResolver resolves dns queries through a DNS-over-HTTPS
+// or DNS-over-TLS endpoint.
+type SecureResolver struct {
+	// dohURL is the DNS-over-HTTPS endpoint, if any
+	dohURL     string
+	httpClient *http.Client
+	// dotAddress is the DNS-over-TLS endpoint, if any
+	dotAddress string
+	dnsClient  *dns.Client
+	// cache contains the addresses of the hosts looked up until
+	// the ttl of their records expires
+	cacheMutex *sync.Mutex
+	cache      map[string]*resolverCacheEntry
+}
+
+// resolverCacheEntry is the cached addresses of a host
+type resolverCacheEntry struct {
+	addresses []string
+	expires   time.Time
+}
+
+// NewSecureResolver creates a new resolver from an endpoint URL.
+//
+// https:// URLs are used as DNS-over-HTTPS endpoints while tls:// URLs
+// are used as DNS-over-TLS endpoints, on port 853 by default.
+func NewSecureResolver(endpoint string, timeout int) (*SecureResolver, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &SecureResolver{
+		cacheMutex: &sync.Mutex{},
+		cache:      make(map[string]*resolverCacheEntry),
+	}
+	switch parsed.Scheme {
+	case "https":
+		resolver.dohURL = endpoint
+		resolver.httpClient = &http.Client{Timeout: time.Duration(timeout) * time.Second}
+	case "tls":
+		port := parsed.Port()
+		if port == "" {
+			port = "853"
+		}
+		resolver.dotAddress = net.JoinHostPort(parsed.Hostname(), port)
+		resolver.dnsClient = &dns.Client{
+			Net:       "tcp-tls",
+			Timeout:   time.Duration(timeout) * time.Second,
+			TLSConfig: &tls.Config{ServerName: parsed.Hostname()},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported secure dns scheme: %s", parsed.Scheme)
+	}
+	return resolver, nil
+}
+
+// Do sends a dns query to the endpoint and returns the response
+func (r *SecureResolver) Do(msg *dns.Msg) (*dns.Msg, error) {
+	if r.dnsClient != nil {
+		resp, _, err := r.dnsClient.Exchange(msg, r.dotAddress)
+		return resp, err
+	}
+
+	// DNS-over-HTTPS requests use a zero id for better caching (RFC 8484)
+	query := msg.Copy()
+	query.Id = 0
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.dohURL, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected doh status code: %d", resp.StatusCode)
+	}
+
+	answer := new(dns.Msg)
+	if err := answer.Unpack(data); err != nil {
+		return nil, err
+	}
+	answer.Id = msg.Id
+	return answer, nil
+}
+
+// LookupHost returns the addresses of a host, from its A records
+// followed by its AAAA records.
+//
+// The addresses are cached for the lowest ttl of their records, up
+// to maxResolverCacheTTL.
+func (r *SecureResolver) LookupHost(host string) ([]string, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{host}, nil
+	}
+
+	r.cacheMutex.Lock()
+	entry, ok := r.cache[host]
+	if ok && time.Now().After(entry.expires) {
+		delete(r.cache, host)
+		ok = false
+	}
+	r.cacheMutex.Unlock()
+	if ok {
+		return entry.addresses, nil
+	}
+
+	addresses, ttl, err := r.lookupHost(host)
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		r.cacheMutex.Lock()
+		r.cache[host] = &resolverCacheEntry{addresses: addresses, expires: time.Now().Add(ttl)}
+		r.cacheMutex.Unlock()
+	}
+	return addresses, nil
+}
+
+// lookupHost queries the addresses of a host along with the
+// duration they can be cached for. The host is resolved as long
+// as the query of either record type succeeds.
+func (r *SecureResolver) lookupHost(host string) ([]string, time.Duration, error) {
+	var addresses []string
+	var lastErr error
+	ttl := maxResolverCacheTTL
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		msg := new(dns.Msg)
+		msg.SetQuestion(dns.Fqdn(host), qtype)
+
+		resp, err := r.Do(msg)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, answer := range resp.Answer {
+			switch record := answer.(type) {
+			case *dns.A:
+				addresses = append(addresses, record.A.String())
+			case *dns.AAAA:
+				addresses = append(addresses, record.AAAA.String())
+			default:
+				continue
+			}
+			if recordTTL := time.Duration(answer.Header().Ttl) * time.Second; recordTTL < ttl {
+				ttl = recordTTL
+			}
+		}
+	}
+	if len(addresses) > 0 {
+		return addresses, ttl, nil
+	}
+	if lastErr != nil {
+		return nil, 0, lastErr
+	}
+	return nil, 0, fmt.Errorf("no addresses found for %s", host)
+}
+
+// dialResult is the result of dialing the addresses of an ip family
+type dialResult struct {
+	conn net.Conn
+	err  error
+}
+
+// dial resolves the host of an address and connects to it with a dialer.
+//
+// Like the dialer does with the system resolver, the addresses of the
+// first ip family resolved are dialed in turn, raced against the addresses
+// of the other family once the fallback delay of the dialer elapsed or
+// the first family failed (RFC 6555). A negative fallback delay dials all
+// the addresses in turn.
+func (r *SecureResolver) dial(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := r.LookupHost(host)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not resolve host")
+	}
+
+	primaries, fallbacks := splitAddressFamilies(addresses)
+	if dialer.FallbackDelay < 0 || len(fallbacks) == 0 {
+		return dialSerial(ctx, dialer, network, addresses, port)
+	}
+	delay := dialer.FallbackDelay
+	if delay == 0 {
+		delay = happyEyeballsDelay
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult, 2)
+	race := func(addresses []string) {
+		conn, err := dialSerial(ctx, dialer, network, addresses, port)
+		results <- dialResult{conn: conn, err: err}
+	}
+	go race(primaries)
+	pending := 1
+
+	fallback := time.NewTimer(delay)
+	defer fallback.Stop()
+	startFallback := func() {
+		if fallbacks != nil {
+			go race(fallbacks)
+			fallbacks = nil
+			pending++
+		}
+	}
+
+	var firstErr error
+	for {
+		select {
+		case <-fallback.C:
+			startFallback()
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				// Close the connection of the other family if it succeeds too
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						if result := <-results; result.conn != nil {
+							result.conn.Close()
+						}
+					}
+				}(pending)
+				return result.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			startFallback()
+			if pending == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
+
+// dialSerial connects to addresses in turn until one succeeds.
+func dialSerial(ctx context.Context, dialer *net.Dialer, network string, addresses []string, port string) (net.Conn, error) {
+	var err error
+	for _, ip := range addresses {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// splitAddressFamilies splits addresses into the addresses of the ip
+// family of the first one and the addresses of the other family.
+func splitAddressFamilies(addresses []string) (primaries, fallbacks []string) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	isIPv4 := func(address string) bool {
+		return net.ParseIP(address).To4() != nil
+	}
+	primaryIPv4 := isIPv4(addresses[0])
+	for _, address := range addresses {
+		if isIPv4(address) == primaryIPv4 {
+			primaries = append(primaries, address)
+		} else {
+			fallbacks = append(fallbacks, address)
+		}
+	}
+	return primaries, fallbacks
+}
@@ -0,0 +1,192 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDoHServer starts a DNS-over-HTTPS server answering the queries
+// with records of a ttl, counting the queries received.
+func newTestDoHServer(t *testing.T, ttl uint32, queries *int32) (*httptest.Server, *SecureResolver) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(queries, 1)
+		if r.URL.Path != "/dns-query" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/dns-message" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		query := new(dns.Msg)
+		if err := query.Unpack(data); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if query.Id != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		answer := new(dns.Msg)
+		answer.SetReply(query)
+		question := query.Question[0]
+		header := dns.RR_Header{Name: question.Name, Class: dns.ClassINET, Rrtype: question.Qtype, Ttl: ttl}
+		switch {
+		case question.Name == "missing.example.com.":
+		case question.Qtype == dns.TypeA:
+			answer.Answer = append(answer.Answer, &dns.A{Hdr: header, A: net.ParseIP("127.0.0.1")})
+		case question.Qtype == dns.TypeAAAA:
+			answer.Answer = append(answer.Answer, &dns.AAAA{Hdr: header, AAAA: net.ParseIP("::1")})
+		}
+		packed, _ := answer.Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(packed)
+	}))
+
+	resolver, err := NewSecureResolver(server.URL+"/dns-query", 5)
+	require.Nil(t, err, "Could not create resolver")
+	resolver.httpClient = server.Client()
+	return server, resolver
+}
+
+func TestSecureResolverDo(t *testing.T) {
+	var queries int32
+	server, resolver := newTestDoHServer(t, 60, &queries)
+	defer server.Close()
+
+	msg := new(dns.Msg)
+	msg.SetQuestion("example.com.", dns.TypeA)
+	resp, err := resolver.Do(msg)
+	require.Nil(t, err, "Could not send doh query")
+	require.Equal(t, msg.Id, resp.Id, "Could not restore query id")
+	require.Len(t, resp.Answer, 1, "Could not get answer")
+	require.Equal(t, "127.0.0.1", resp.Answer[0].(*dns.A).A.String(), "Could not get address")
+
+	resolver.dohURL = server.URL + "/missing"
+	_, err = resolver.Do(msg)
+	require.NotNil(t, err, "Could not fail on unexpected status code")
+}
+
+func TestSecureResolverLookupHostCache(t *testing.T) {
+	var queries int32
+	server, resolver := newTestDoHServer(t, 60, &queries)
+	defer server.Close()
+
+	addresses, err := resolver.LookupHost("example.com")
+	require.Nil(t, err, "Could not lookup host")
+	require.Equal(t, []string{"127.0.0.1", "::1"}, addresses, "Could not get ipv4 and ipv6 addresses")
+	require.Equal(t, int32(2), atomic.LoadInt32(&queries), "Could not query the resolver")
+
+	addresses, err = resolver.LookupHost("example.com")
+	require.Nil(t, err, "Could not lookup cached host")
+	require.Equal(t, []string{"127.0.0.1", "::1"}, addresses, "Could not get cached addresses")
+	require.Equal(t, int32(2), atomic.LoadInt32(&queries), "Could not cache the addresses")
+
+	_, err = resolver.LookupHost("missing.example.com")
+	require.NotNil(t, err, "Could not fail on missing host")
+
+	addresses, err = resolver.LookupHost("127.0.0.2")
+	require.Nil(t, err, "Could not lookup ip")
+	require.Equal(t, []string{"127.0.0.2"}, addresses, "Could not return ip as is")
+}
+
+func TestSecureResolverLookupHostNoCache(t *testing.T) {
+	var queries int32
+	server, resolver := newTestDoHServer(t, 0, &queries)
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err := resolver.LookupHost("example.com")
+		require.Nil(t, err, "Could not lookup host")
+	}
+	require.Equal(t, int32(4), atomic.LoadInt32(&queries), "Cached addresses of zero ttl")
+}
+
+// failingDNSClient fails a number of queries before answering them
+type failingDNSClient struct {
+	failures int
+	queries  int
+}
+
+func (c *failingDNSClient) Do(msg *dns.Msg) (*dns.Msg, error) {
+	if c.queries++; c.queries <= c.failures {
+		return nil, errors.New("failed query")
+	}
+	return new(dns.Msg).SetReply(msg), nil
+}
+
+func TestRetryingDNSClient(t *testing.T) {
+	msg := new(dns.Msg).SetQuestion("example.com.", dns.TypeA)
+
+	client := &failingDNSClient{failures: 2}
+	_, err := (&retryingDNSClient{client: client, retries: 3}).Do(msg)
+	require.Nil(t, err, "Could not retry failed queries")
+	require.Equal(t, 3, client.queries, "Could not send all the attempts")
+
+	client = &failingDNSClient{failures: 2}
+	_, err = (&retryingDNSClient{client: client, retries: 2}).Do(msg)
+	require.NotNil(t, err, "Could not fail once the retries are exhausted")
+	require.Equal(t, 2, client.queries, "Could not limit the attempts")
+
+	client = &failingDNSClient{}
+	_, err = (&retryingDNSClient{client: client}).Do(msg)
+	require.Nil(t, err, "Could not send query without retries")
+	require.Equal(t, 1, client.queries, "Could not send a single attempt")
+}
+
+func TestSplitAddressFamilies(t *testing.T) {
+	primaries, fallbacks := splitAddressFamilies([]string{"127.0.0.1", "::1", "127.0.0.2", "::2"})
+	require.Equal(t, []string{"127.0.0.1", "127.0.0.2"}, primaries, "Could not get first family addresses")
+	require.Equal(t, []string{"::1", "::2"}, fallbacks, "Could not get other family addresses")
+
+	primaries, fallbacks = splitAddressFamilies([]string{"::1", "127.0.0.1"})
+	require.Equal(t, []string{"::1"}, primaries, "Could not use first address family")
+	require.Equal(t, []string{"127.0.0.1"}, fallbacks, "Could not get ipv4 fallbacks")
+}
+
+func TestSecureResolverDialFallback(t *testing.T) {
+	listener, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skip("ipv6 loopback is not available")
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	// The ipv4 address can't be reached, so the ipv6 one is dialed once it
+	// failed or the fallback delay elapsed, before the connect timeout
+	resolver, err := NewSecureResolver("https://127.0.0.1/dns-query", 5)
+	require.Nil(t, err, "Could not create resolver")
+	resolver.cache["dual.test"] = &resolverCacheEntry{
+		addresses: []string{"192.0.2.1", "::1"},
+		expires:   time.Now().Add(time.Minute),
+	}
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second, FallbackDelay: 50 * time.Millisecond}
+	start := time.Now()
+	conn, err := resolver.dial(context.Background(), dialer, "tcp", net.JoinHostPort("dual.test", port))
+	require.Nil(t, err, "Could not dial fallback address")
+	conn.Close()
+	require.Less(t, int64(time.Since(start)), int64(2*time.Second), "Could not race the fallback address")
+	require.Equal(t, "::1", conn.RemoteAddr().(*net.TCPAddr).IP.String(), "Could not connect to fallback address")
+}
@@ -0,0 +1,221 @@
+package executor
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostThrottle detects the hosts blocking or rate limiting the scan and
+// slows down the requests sent to them, doubling the delay between the
+// requests with every block and decreasing it as responses get through.
+//
+// Hosts which keep blocking the requests at the maximum delay are paused
+// for the rest of the scan. A nil throttle never slows down requests.
+type HostThrottle struct {
+	mutex *sync.Mutex
+	hosts map[string]*hostState
+	// onBlock is optionally called when a host blocks a request
+	onBlock func(host string, stats *HostStats)
+}
+
+// hostState is the throttling state of a host
+type hostState struct {
+	delay time.Duration
+	// next is the time the next request to the host can be sent at
+	next time.Time
+	// consecutive is the number of blocks received in a row
+	consecutive int
+	stats       HostStats
+}
+
+// HostStats contains the throttling statistics of a host.
+type HostStats struct {
+	// Host is the host the statistics are for
+	Host string `json:"host"`
+	// Blocks is the number of responses detected as blocks
+	Blocks int `json:"blocks"`
+	// Delay is the current delay between the requests to the host
+	Delay time.Duration `json:"-"`
+	// Paused specifies whether the requests to the host were stopped
+	Paused bool `json:"paused"`
+}
+
+const (
+	// minThrottleDelay is the delay used after the first block of a host
+	minThrottleDelay = 1 * time.Second
+	// maxThrottleDelay is the maximum delay between the requests to a host
+	maxThrottleDelay = 30 * time.Second
+	// maxConsecutiveBlocks is the number of blocks in a row after which
+	// the requests to a host are stopped
+	maxConsecutiveBlocks = 10
+)
+
+// NewHostThrottle creates a new host throttle, calling onBlock, if any,
+// with the statistics of a host every time it blocks a request.
+func NewHostThrottle(onBlock func(host string, stats *HostStats)) *HostThrottle {
+	return &HostThrottle{
+		mutex:   &sync.Mutex{},
+		hosts:   make(map[string]*hostState),
+		onBlock: onBlock,
+	}
+}
+
+// Wait waits until a request can be sent to a host, returning
+// false if the requests to the host were stopped.
+func (t *HostThrottle) Wait(host string) bool {
+	if t == nil {
+		return true
+	}
+
+	t.mutex.Lock()
+	state, ok := t.hosts[host]
+	if !ok {
+		t.mutex.Unlock()
+		return true
+	}
+	if state.stats.Paused {
+		t.mutex.Unlock()
+		return false
+	}
+
+	// Space the requests to the host by the delay
+	now := time.Now()
+	next := state.next
+	if next.Before(now) {
+		next = now
+	}
+	state.next = next.Add(state.delay)
+	t.mutex.Unlock()
+
+	time.Sleep(next.Sub(now))
+	return true
+}
+
+// Observe updates the state of a host from a response received from it.
+func (t *HostThrottle) Observe(host string, resp *http.Response, body string) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	state, ok := t.hosts[host]
+	if !isBlockResponse(resp, body) {
+		// Speed up again once the responses get through
+		if ok {
+			state.consecutive = 0
+			state.delay /= 2
+			if state.delay < minThrottleDelay {
+				state.delay = 0
+			}
+			state.stats.Delay = state.delay
+		}
+		t.mutex.Unlock()
+		return
+	}
+
+	if !ok {
+		state = &hostState{stats: HostStats{Host: host}}
+		t.hosts[host] = state
+	}
+	state.consecutive++
+	state.delay *= 2
+	if state.delay < minThrottleDelay {
+		state.delay = minThrottleDelay
+	}
+	if retryAfter := getRetryAfter(resp); retryAfter > state.delay {
+		state.delay = retryAfter
+	}
+	if state.delay > maxThrottleDelay {
+		state.delay = maxThrottleDelay
+	}
+	state.next = time.Now().Add(state.delay)
+
+	state.stats.Blocks++
+	state.stats.Delay = state.delay
+	state.stats.Paused = state.consecutive >= maxConsecutiveBlocks
+	stats := state.stats
+	t.mutex.Unlock()
+
+	if t.onBlock != nil {
+		t.onBlock(host, &stats)
+	}
+}
+
+// Stats returns the statistics of the hosts which blocked requests, sorted by host.
+func (t *HostThrottle) Stats() []*HostStats {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stats := make([]*HostStats, 0, len(t.hosts))
+	for _, state := range t.hosts {
+		hostStats := state.stats
+		stats = append(stats, &hostStats)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Host < stats[j].Host
+	})
+	return stats
+}
+
+// blockSignatures are the lowercase strings present in the
+// block and challenge pages of common firewalls.
+var blockSignatures = []string{
+	"captcha",
+	"cf-chl-",
+	"attention required! | cloudflare",
+	"just a moment...",
+	"access denied | ",
+	"request unsuccessful. incapsula",
+	"the requested url was rejected",
+	"sucuri website firewall",
+}
+
+// isBlockResponse returns true if a response is a rate limit
+// or a block or challenge page of a firewall.
+func isBlockResponse(resp *http.Response, body string) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden, http.StatusServiceUnavailable:
+	default:
+		return false
+	}
+
+	if resp.Header.Get("Cf-Mitigated") == "challenge" {
+		return true
+	}
+	lower := strings.ToLower(body)
+	for _, signature := range blockSignatures {
+		if strings.Contains(lower, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// getRetryAfter returns the delay asked by the Retry-After header of a
+// response, or zero if there is none or the date asked is past.
+func getRetryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = time.Until(date)
+	}
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
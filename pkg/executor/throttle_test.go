@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBlockResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		body   string
+		block  bool
+	}{
+		{"rate limit", http.StatusTooManyRequests, http.Header{}, "", true},
+		{"captcha", http.StatusForbidden, http.Header{}, "<html>Please solve the CAPTCHA</html>", true},
+		{"challenge", http.StatusForbidden, http.Header{"Cf-Mitigated": []string{"challenge"}}, "", true},
+		{"unavailable challenge", http.StatusServiceUnavailable, http.Header{}, "<title>Just a moment...</title>", true},
+		{"forbidden", http.StatusForbidden, http.Header{}, "<html>Forbidden</html>", false},
+		{"ok captcha", http.StatusOK, http.Header{}, "captcha", false},
+	}
+	for _, test := range tests {
+		resp := &http.Response{StatusCode: test.status, Header: test.header}
+		require.Equal(t, test.block, isBlockResponse(resp, test.body), "Could not detect block for %s", test.name)
+	}
+}
+
+func TestGetRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"none", "", 0, 0},
+		{"seconds", "120", 120 * time.Second, 120 * time.Second},
+		{"negative seconds", "-5", 0, 0},
+		{"future date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), 58 * time.Minute, time.Hour},
+		{"past date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, 0},
+		{"invalid", "soon", 0, 0},
+	}
+	for _, test := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if test.value != "" {
+			resp.Header.Set("Retry-After", test.value)
+		}
+		delay := getRetryAfter(resp)
+		require.True(t, delay >= test.min && delay <= test.max, "Could not get retry delay for %s: %s", test.name, delay)
+	}
+}
+
+func TestHostThrottleObserve(t *testing.T) {
+	var blocks []HostStats
+	throttle := NewHostThrottle(func(host string, stats *HostStats) {
+		blocks = append(blocks, *stats)
+	})
+	blocked := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	passed := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+
+	throttle.Observe("example.com", passed, "")
+	require.Empty(t, throttle.Stats(), "Could not ignore passing response of unthrottled host")
+
+	throttle.Observe("example.com", blocked, "")
+	require.Equal(t, minThrottleDelay, throttle.hosts["example.com"].delay, "Could not throttle host on first block")
+	throttle.Observe("example.com", blocked, "")
+	require.Equal(t, 2*minThrottleDelay, throttle.hosts["example.com"].delay, "Could not double delay on block")
+
+	throttle.Observe("example.com", passed, "")
+	require.Equal(t, minThrottleDelay, throttle.hosts["example.com"].delay, "Could not halve delay on passing response")
+	throttle.Observe("example.com", passed, "")
+	require.Equal(t, time.Duration(0), throttle.hosts["example.com"].delay, "Could not remove delay below minimum")
+
+	for i := 0; i < maxConsecutiveBlocks; i++ {
+		throttle.Observe("example.com", blocked, "")
+	}
+	state := throttle.hosts["example.com"]
+	require.Equal(t, maxThrottleDelay, state.delay, "Could not cap delay")
+	require.True(t, state.stats.Paused, "Could not pause host after consecutive blocks")
+	require.False(t, throttle.Wait("example.com"), "Could send request to paused host")
+	require.True(t, throttle.Wait("other.com"), "Could not send request to other host")
+
+	require.Len(t, blocks, 2+maxConsecutiveBlocks, "Could not call block callback")
+	require.Equal(t, 2+maxConsecutiveBlocks, blocks[len(blocks)-1].Blocks, "Could not count blocks")
+
+	retry := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"5"}}}
+	throttle.Observe("retry.com", retry, "")
+	require.Equal(t, 5*time.Second, throttle.hosts["retry.com"].delay, "Could not use retry delay")
+}
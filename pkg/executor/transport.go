@@ -0,0 +1,147 @@
+package executor
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/proxy"
+)
+
+// maxObservedBodySize is the number of bytes of the forbidden and
+// unavailable responses read to detect the block pages.
+const maxObservedBodySize = 64 * 1024
+
+// TransportOptions contains configuration options for the transport
+// connecting to the hosts.
+type TransportOptions struct {
+	// Timeout is the seconds to wait for a response, used as connect
+	// timeout if the dialer has none.
+	Timeout       int
+	ProxyURL      string
+	ProxySocksURL string
+	// ProxyAuth is the authentication scheme of the http proxy, basic or ntlm.
+	ProxyAuth string
+	// Resolver optionally resolves the hosts through a secure resolver.
+	Resolver *SecureResolver
+	// Dialer optionally configures the connections to the hosts.
+	Dialer *DialerOptions
+}
+
+// NewTransport creates a transport connecting to the hosts like the
+// http executors do, through the proxies, resolver and dialer of the
+// options.
+func NewTransport(options *TransportOptions) (*http.Transport, error) {
+	var proxyURL *url.URL
+	if options.ProxyURL != "" {
+		var err error
+		if proxyURL, err = url.Parse(options.ProxyURL); err != nil {
+			return nil, err
+		}
+	}
+	return makeTransport(proxyURL, options), nil
+}
+
+// makeTransport creates a transport from the options
+func makeTransport(proxyURL *url.URL, options *TransportOptions) *http.Transport {
+	transport := &http.Transport{
+		MaxIdleConnsPerHost: -1,
+		TLSClientConfig: &tls.Config{
+			Renegotiation:      tls.RenegotiateOnceAsClient,
+			InsecureSkipVerify: true,
+		},
+		DisableKeepAlives: true,
+	}
+
+	// Resolve the hosts through the secure resolver if any
+	dialer := makeDialer(options.Dialer, options.Timeout)
+	transport.DialContext = makeDialContext(dialer, options.Resolver)
+	transport.TLSHandshakeTimeout = dialer.Timeout
+
+	// Attempts to overwrite the dial function with the socks proxied version
+	if options.ProxySocksURL != "" {
+		var proxyAuth *proxy.Auth
+		socksURL, err := url.Parse(options.ProxySocksURL)
+		if err == nil {
+			proxyAuth = &proxy.Auth{}
+			proxyAuth.User = socksURL.User.Username()
+			proxyAuth.Password, _ = socksURL.User.Password()
+		}
+		socksDialer, err := proxy.SOCKS5("tcp", fmt.Sprintf("%s:%s", socksURL.Hostname(), socksURL.Port()), proxyAuth, dialer)
+		if contextDialer, ok := socksDialer.(proxy.ContextDialer); err == nil && ok {
+			transport.DialContext = contextDialer.DialContext
+		}
+	}
+
+	if proxyURL != nil {
+		if options.ProxyAuth == ProxyAuthNTLM {
+			transport.DialContext = newNTLMProxyDialer(proxyURL, transport.DialContext).DialContext
+		} else {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+	return transport
+}
+
+// limitedTransport sends the requests of a transport under a request
+// budget and a host throttle.
+type limitedTransport struct {
+	transport http.RoundTripper
+	budget    *RequestBudget
+	throttle  *HostThrottle
+}
+
+// NewLimitedTransport wraps a transport so that its requests are slowed
+// down by the throttle, if any, and count against the budget, if any,
+// failing once the host is paused or its budget exhausted. The responses
+// are observed by the throttle.
+func NewLimitedTransport(transport http.RoundTripper, budget *RequestBudget, throttle *HostThrottle) http.RoundTripper {
+	return &limitedTransport{transport: transport, budget: budget, throttle: throttle}
+}
+
+// RoundTrip sends a request once the throttle and budget allow it
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if !t.throttle.Wait(host) {
+		return nil, fmt.Errorf("requests paused for blocking host %s", host)
+	}
+	if !t.budget.Take(host) {
+		return nil, fmt.Errorf("request budget exhausted for %s", host)
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || t.throttle == nil {
+		return resp, err
+	}
+
+	// Only the forbidden and unavailable responses are checked for block
+	// pages, so only their start is read ahead of the caller
+	var body []byte
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusServiceUnavailable {
+		body, err = ioutil.ReadAll(io.LimitReader(resp.Body, maxObservedBodySize))
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		resp.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+	}
+	t.throttle.Observe(host, resp, unsafeToString(body))
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the transport
+func (t *limitedTransport) CloseIdleConnections() {
+	if transport, ok := t.transport.(interface{ CloseIdleConnections() }); ok {
+		transport.CloseIdleConnections()
+	}
+}
+
+// readCloser reads from a reader and closes a closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,48 @@
+package executor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitedTransport(t *testing.T) {
+	page := "<html><title>Attention Required! | Cloudflare</title>" + strings.Repeat("x", maxObservedBodySize) + "</html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/blocked" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, page)
+		}
+	}))
+	defer server.Close()
+
+	transport, err := NewTransport(&TransportOptions{Timeout: 5})
+	require.Nil(t, err, "Could not create transport")
+	throttle := NewHostThrottle(nil)
+	client := &http.Client{Transport: NewLimitedTransport(transport, NewRequestBudget(2), throttle)}
+
+	resp, err := client.Get(server.URL + "/blocked")
+	require.Nil(t, err, "Could not send request")
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Nil(t, err, "Could not read body")
+	require.Equal(t, page, string(body), "Could not read whole body after observing it")
+
+	stats := throttle.Stats()
+	require.Len(t, stats, 1, "Could not observe block page")
+	require.Equal(t, 1, stats[0].Blocks, "Could not count block")
+
+	// Reset the throttle delay to test the budget
+	throttle.hosts[stats[0].Host] = &hostState{}
+	resp, err = client.Get(server.URL)
+	require.Nil(t, err, "Could not send request within budget")
+	resp.Body.Close()
+
+	_, err = client.Get(server.URL)
+	require.NotNil(t, err, "Could not stop requests once the budget is exhausted")
+}
@@ -3,6 +3,7 @@ package extractors
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 // CompileExtractors performs the initial setup operation on a extractor
@@ -23,7 +24,10 @@ func (e *Extractor) CompileExtractors() error {
 	}
 
 	// Setup the part of the request to match, if any.
-	if e.Part != "" {
+	if strings.HasPrefix(e.Part, MetaPartPrefix) {
+		e.part = MetaPart
+		e.metaName = strings.ToLower(strings.TrimPrefix(e.Part, MetaPartPrefix))
+	} else if e.Part != "" {
 		e.part, ok = PartTypes[e.Part]
 		if !ok {
 			return fmt.Errorf("unknown matcher part specified: %s", e.Part)
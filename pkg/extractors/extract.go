@@ -1,9 +1,21 @@
 package extractors
 
-// Extract extracts response from the parts of request using a regex
-func (e *Extractor) Extract(body, headers string) map[string]struct{} {
+// Extract extracts response from the parts of request using a regex.
+//
+// data contains the html title and meta values parsed from the body and
+// the decoded json web tokens for extractors asking for them.
+func (e *Extractor) Extract(body, headers string, data map[string]interface{}) map[string]struct{} {
 	// Match the parts as required for regex check
-	if e.part == BodyPart {
+	if e.part == TitlePart || e.part == MetaPart || e.part == JWTPart {
+		key := "title"
+		if e.part == MetaPart {
+			key = MetaPartPrefix + e.metaName
+		} else if e.part == JWTPart {
+			key = "jwt"
+		}
+		value, _ := data[key].(string)
+		return e.extractRegex(value)
+	} else if e.part == BodyPart {
 		return e.extractRegex(body)
 	} else if e.part == HeaderPart {
 		return e.extractRegex(headers)
@@ -0,0 +1,29 @@
+package extractors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractHTMLParts(t *testing.T) {
+	data := map[string]interface{}{"title": "Admin Login", "meta:generator": "WordPress 5.4"}
+
+	tests := []struct {
+		part    string
+		regex   string
+		results map[string]struct{}
+	}{
+		{"title", `[A-Z][a-z]+`, map[string]struct{}{"Admin": {}, "Login": {}}},
+		{"meta:Generator", `[0-9.]+`, map[string]struct{}{"5.4": {}}},
+		{"meta:description", `.+`, map[string]struct{}{}},
+	}
+	for _, test := range tests {
+		e := &Extractor{Type: "regex", Part: test.part, Regex: []string{test.regex}}
+		require.Nil(t, e.CompileExtractors(), "Could not compile extractor for part %s", test.part)
+		require.Equal(t, test.results, e.Extract("body", "", data), "Could not extract part %s", test.part)
+	}
+
+	e := &Extractor{Type: "regex", Part: "metas", Regex: []string{".+"}}
+	require.NotNil(t, e.CompileExtractors(), "Could compile unknown part")
+}
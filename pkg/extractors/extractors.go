@@ -6,6 +6,9 @@ import "regexp"
 type Extractor struct {
 	// Type is the type of the matcher
 	Type string `yaml:"type"`
+	// Name is the optional name of the extractor, naming the file
+	// its values are written to with -extract-output.
+	Name string `yaml:"name,omitempty"`
 
 	// Regex are the regex pattern required to be present in the response
 	Regex []string `yaml:"regex"`
@@ -18,6 +21,8 @@ type Extractor struct {
 	Part string `yaml:"part,omitempty"`
 	// part is the part of the request to match
 	part Part
+	// metaName is the name of the meta tag to extract from for meta parts
+	metaName string
 }
 
 // ExtractorType is the type of the extractor specified
@@ -43,15 +48,28 @@ const (
 	HeaderPart
 	// AllPart matches both response body and headers of the response.
 	AllPart
+	// TitlePart matches the html title of the response.
+	TitlePart
+	// MetaPart matches the content of a html meta tag of the response.
+	MetaPart
+	// JWTPart matches the json web tokens decoded from the response.
+	JWTPart
 )
 
 // PartTypes is an table for conversion of part type from string.
+//
+// Meta parts are specified as meta:<name> and are not part of the table.
 var PartTypes = map[string]Part{
 	"body":   BodyPart,
 	"header": HeaderPart,
 	"all":    AllPart,
+	"title":  TitlePart,
+	"jwt":    JWTPart,
 }
 
+// MetaPartPrefix is the prefix of the parts matching html meta tags.
+const MetaPartPrefix = "meta:"
+
 // GetPart returns the part of the matcher
 func (e *Extractor) GetPart() Part {
 	return e.part
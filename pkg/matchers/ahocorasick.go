@@ -0,0 +1,141 @@
+package matchers
+
+// wordsAutomaton is an Aho-Corasick automaton matching all the words
+// of a matcher in a single pass over the corpus.
+type wordsAutomaton struct {
+	nodes []*automatonNode
+	// words is the number of unique words in the automaton
+	words int
+	// emptyWords is the number of unique empty words, which match any corpus
+	emptyWords int
+}
+
+// automatonNode is a state of the automaton
+type automatonNode struct {
+	children map[byte]int
+	// fail is the state to fall back to when no child matches
+	fail int
+	// output is the nearest state in the fail chain ending a word, or -1
+	output int
+	// word is the index of the word ending at this state, or -1
+	word int
+}
+
+// newWordsAutomaton builds an automaton from a list of words
+func newWordsAutomaton(words []string) *wordsAutomaton {
+	a := &wordsAutomaton{nodes: []*automatonNode{newAutomatonNode()}}
+
+	seen := make(map[string]struct{})
+	for _, word := range words {
+		if _, ok := seen[word]; ok {
+			continue
+		}
+		seen[word] = struct{}{}
+
+		if word == "" {
+			a.emptyWords++
+			continue
+		}
+
+		state := 0
+		for i := 0; i < len(word); i++ {
+			next, ok := a.nodes[state].children[word[i]]
+			if !ok {
+				next = len(a.nodes)
+				a.nodes = append(a.nodes, newAutomatonNode())
+				a.nodes[state].children[word[i]] = next
+			}
+			state = next
+		}
+		a.nodes[state].word = a.words
+		a.words++
+	}
+	a.words += a.emptyWords
+
+	// Compute the fail and output links breadth first
+	queue := make([]int, 0, len(a.nodes))
+	for _, child := range a.nodes[0].children {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		for c, child := range a.nodes[state].children {
+			queue = append(queue, child)
+
+			fail := a.nodes[state].fail
+			for fail != 0 {
+				if _, ok := a.nodes[fail].children[c]; ok {
+					break
+				}
+				fail = a.nodes[fail].fail
+			}
+			if next, ok := a.nodes[fail].children[c]; ok && next != child {
+				fail = next
+			}
+			a.nodes[child].fail = fail
+
+			if a.nodes[fail].word != -1 {
+				a.nodes[child].output = fail
+			} else {
+				a.nodes[child].output = a.nodes[fail].output
+			}
+		}
+	}
+	return a
+}
+
+func newAutomatonNode() *automatonNode {
+	return &automatonNode{children: make(map[byte]int), output: -1, word: -1}
+}
+
+// matchAny returns true if any of the words is present in the corpus
+func (a *wordsAutomaton) matchAny(corpus string) bool {
+	if a.emptyWords > 0 {
+		return true
+	}
+	return a.search(corpus, func(_ int) bool { return true })
+}
+
+// matchAll returns true if all of the words are present in the corpus
+func (a *wordsAutomaton) matchAll(corpus string) bool {
+	remaining := a.words - a.emptyWords
+	if remaining == 0 {
+		return true
+	}
+
+	found := make([]bool, a.words)
+	return a.search(corpus, func(word int) bool {
+		if !found[word] {
+			found[word] = true
+			remaining--
+		}
+		return remaining == 0
+	})
+}
+
+// search runs the automaton over the corpus calling found for every
+// word occurrence until it returns true.
+func (a *wordsAutomaton) search(corpus string, found func(word int) bool) bool {
+	state := 0
+	for i := 0; i < len(corpus); i++ {
+		c := corpus[i]
+		for state != 0 {
+			if _, ok := a.nodes[state].children[c]; ok {
+				break
+			}
+			state = a.nodes[state].fail
+		}
+		if next, ok := a.nodes[state].children[c]; ok {
+			state = next
+		}
+
+		for output := state; output > 0; output = a.nodes[output].output {
+			if a.nodes[output].word != -1 && found(a.nodes[output].word) {
+				return true
+			}
+		}
+	}
+	return false
+}
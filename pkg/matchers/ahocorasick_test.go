@@ -0,0 +1,41 @@
+package matchers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordsAutomaton(t *testing.T) {
+	words := []string{"he", "she", "his", "hers", "usher", "she"}
+	automaton := newWordsAutomaton(words)
+
+	corpuses := []string{"ushers", "ahishers", "sh", "h", "xshex", "", "hershe"}
+	for _, corpus := range corpuses {
+		anyMatched, allMatched := false, true
+		for _, word := range words {
+			if strings.Contains(corpus, word) {
+				anyMatched = true
+			} else {
+				allMatched = false
+			}
+		}
+		require.Equal(t, anyMatched, automaton.matchAny(corpus), "Could not match any word in %s", corpus)
+		require.Equal(t, allMatched, automaton.matchAll(corpus), "Could not match all words in %s", corpus)
+	}
+}
+
+func TestCompiledWordsCondition(t *testing.T) {
+	m := &Matcher{Type: "word", Condition: "and", Words: []string{"a", "b"}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile matcher")
+
+	require.True(t, m.matchWords("a b"), "Could not match valid AND condition")
+	require.False(t, m.matchWords("b"), "Could match invalid AND condition")
+
+	m = &Matcher{Type: "word", Words: []string{"a", "b"}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile matcher")
+
+	require.True(t, m.matchWords("b"), "Could not match valid OR condition")
+	require.False(t, m.matchWords("c"), "Could match invalid OR condition")
+}
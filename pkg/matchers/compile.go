@@ -22,6 +22,24 @@ func (m *Matcher) CompileMatchers() error {
 		return fmt.Errorf("unknown matcher type specified: %s", m.Type)
 	}
 
+	// Load the words from the words file, using the automaton of the
+	// file if the matcher has no other words
+	if m.WordsFile != "" {
+		file, err := loadWordsFile(m.WordsFile)
+		if err != nil {
+			return fmt.Errorf("could not load words file %s: %s", m.WordsFile, err)
+		}
+		if len(m.Words) == 0 {
+			m.wordsAutomaton = file.automaton
+		}
+		m.Words = append(m.Words, file.words...)
+	}
+
+	// Compile the words into an automaton matching them in a single pass
+	if len(m.Words) > 0 && m.wordsAutomaton == nil {
+		m.wordsAutomaton = newWordsAutomaton(m.Words)
+	}
+
 	// Compile the regexes
 	for _, regex := range m.Regex {
 		compiled, err := regexp.Compile(regex)
@@ -32,6 +50,27 @@ func (m *Matcher) CompileMatchers() error {
 		m.regexCompiled = append(m.regexCompiled, compiled)
 	}
 
+	if err := ValidateContentTypes(m.ContentType); err != nil {
+		return err
+	}
+
+	// Setup the hash algorithm of hash matchers
+	if m.matcherType == HashMatcher {
+		m.hashAlgorithm, ok = HashAlgorithms[strings.ToLower(m.Algorithm)]
+		if !ok {
+			return fmt.Errorf("unknown hash algorithm specified: %s", m.Algorithm)
+		}
+	}
+
+	// Compile the latency thresholds
+	for _, latency := range m.Latency {
+		threshold, err := parseLatency(latency)
+		if err != nil {
+			return err
+		}
+		m.latencyCompiled = append(m.latencyCompiled, threshold)
+	}
+
 	// Compile the dsl expressions
 	for _, dsl := range m.DSL {
 		compiled, err := govaluate.NewEvaluableExpressionWithFunctions(dsl, helperFunctions())
@@ -40,6 +79,11 @@ func (m *Matcher) CompileMatchers() error {
 		}
 
 		m.dslCompiled = append(m.dslCompiled, compiled)
+		for _, variable := range compiled.Vars() {
+			if strings.HasPrefix(variable, "jwt") {
+				m.dslJWT = true
+			}
+		}
 	}
 
 	// Setup the condition type, if any.
@@ -53,7 +97,10 @@ func (m *Matcher) CompileMatchers() error {
 	}
 
 	// Setup the part of the request to match, if any.
-	if m.Part != "" {
+	if strings.HasPrefix(m.Part, MetaPartPrefix) {
+		m.part = MetaPart
+		m.metaName = strings.ToLower(strings.TrimPrefix(m.Part, MetaPartPrefix))
+	} else if m.Part != "" {
 		m.part, ok = PartTypes[m.Part]
 		if !ok {
 			return fmt.Errorf("unknown matcher part specified: %s", m.Part)
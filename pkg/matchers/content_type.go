@@ -0,0 +1,70 @@
+package matchers
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// GetContentType returns the mime type of a response from its
+// Content-Type header, sniffing it from the body if missing.
+func GetContentType(resp *http.Response, body string) string {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType([]byte(body))
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+}
+
+// ValidateContentTypes validates mime type patterns, such as
+// application/json or image/*, optionally prefixed by ! to exclude them.
+func ValidateContentTypes(patterns []string) error {
+	for _, pattern := range patterns {
+		if !strings.Contains(strings.TrimPrefix(pattern, "!"), "/") {
+			return fmt.Errorf("invalid content type specified: %s", pattern)
+		}
+	}
+	return nil
+}
+
+// MatchContentType returns true if a mime type matches one of the
+// patterns, if any, and none of the excluded patterns.
+func MatchContentType(patterns []string, contentType string) bool {
+	included, hasIncluded := false, false
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if strings.HasPrefix(pattern, "!") {
+			if matchMimePattern(pattern[1:], contentType) {
+				return false
+			}
+			continue
+		}
+		hasIncluded = true
+		if matchMimePattern(pattern, contentType) {
+			included = true
+		}
+	}
+	return included || !hasIncluded
+}
+
+// matchMimePattern matches a mime type against a pattern,
+// which can use * as the type or subtype.
+func matchMimePattern(pattern, contentType string) bool {
+	patternType, patternSubtype := splitMimeType(pattern)
+	mimeType, mimeSubtype := splitMimeType(contentType)
+	return (patternType == "*" || patternType == mimeType) &&
+		(patternSubtype == "*" || patternSubtype == mimeSubtype)
+}
+
+// splitMimeType splits a mime type into its type and subtype
+func splitMimeType(value string) (string, string) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
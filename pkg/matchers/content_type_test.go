@@ -0,0 +1,27 @@
+package matchers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchContentType(t *testing.T) {
+	require.True(t, MatchContentType([]string{"application/json"}, "application/json"), "Could not match mime type")
+	require.False(t, MatchContentType([]string{"application/json"}, "text/html"), "Could match other mime type")
+	require.True(t, MatchContentType([]string{"text/*"}, "text/html"), "Could not match wildcard subtype")
+	require.False(t, MatchContentType([]string{"!image/*", "!video/*"}, "image/png"), "Could match excluded mime type")
+	require.True(t, MatchContentType([]string{"!image/*", "!video/*"}, "text/html"), "Could not match not excluded mime type")
+	require.True(t, MatchContentType(nil, "image/png"), "Could not match without patterns")
+
+	require.NotNil(t, ValidateContentTypes([]string{"json"}), "Could validate invalid pattern")
+}
+
+func TestGetContentType(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Content-Type": []string{"Application/JSON; charset=utf-8"}}}
+	require.Equal(t, "application/json", GetContentType(resp, ""), "Could not parse content type")
+
+	resp = &http.Response{Header: http.Header{}}
+	require.Equal(t, "image/png", GetContentType(resp, "\x89PNG\r\n\x1a\n"), "Could not sniff content type")
+}
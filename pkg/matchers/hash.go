@@ -0,0 +1,113 @@
+package matchers
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// HashAlgorithm is a function returning the digest of a corpus as a string
+type HashAlgorithm func(corpus string) string
+
+// HashAlgorithms is a table of the algorithms supported by hash matchers.
+var HashAlgorithms = map[string]HashAlgorithm{
+	"md5": func(corpus string) string {
+		hash := md5.Sum([]byte(corpus))
+		return hex.EncodeToString(hash[:])
+	},
+	"sha1": func(corpus string) string {
+		hash := sha1.Sum([]byte(corpus))
+		return hex.EncodeToString(hash[:])
+	},
+	"sha256": func(corpus string) string {
+		hash := sha256.Sum256([]byte(corpus))
+		return hex.EncodeToString(hash[:])
+	},
+	"mmh3": func(corpus string) string {
+		return strconv.Itoa(int(int32(murmur3([]byte(base64Lines(corpus))))))
+	},
+}
+
+// matchHash matches the digest of a corpus against the hashes of the matcher
+func (m *Matcher) matchHash(corpus string) bool {
+	digest := m.hashAlgorithm(corpus)
+
+	// Hashes don't support AND conditions.
+	for _, hash := range m.Hash {
+		if strings.EqualFold(strings.TrimSpace(hash), digest) {
+			return true
+		}
+	}
+	return false
+}
+
+// base64Lines encodes a corpus to base64 with a newline every 76
+// characters and at the end, as done by python's base64.encodebytes.
+//
+// This is the encoding used by shodan when computing mmh3 favicon hashes.
+func base64Lines(corpus string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(corpus))
+
+	builder := &strings.Builder{}
+	for i := 0; i < len(encoded); i += 76 {
+		end := i + 76
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		builder.WriteString(encoded[i:end])
+		builder.WriteRune('\n')
+	}
+	return builder.String()
+}
+
+// murmur3 returns the 32 bit murmur3 hash of data with a zero seed
+func murmur3(data []byte) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+	var hash uint32
+
+	blocks := len(data) / 4
+	for i := 0; i < blocks; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+
+		hash ^= k
+		hash = bits.RotateLeft32(hash, 13)
+		hash = hash*5 + 0xe6546b64
+	}
+
+	var k uint32
+	tail := data[blocks*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		hash ^= k
+	}
+
+	hash ^= uint32(len(data))
+	hash ^= hash >> 16
+	hash *= 0x85ebca6b
+	hash ^= hash >> 13
+	hash *= 0xc2b2ae35
+	hash ^= hash >> 16
+	return hash
+}
@@ -0,0 +1,24 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashMatcher(t *testing.T) {
+	m := &Matcher{Type: "hash", Algorithm: "md5", Hash: []string{"5D41402ABC4B2A76B9719D911017C592"}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile matcher")
+
+	require.True(t, m.matchHash("hello"), "Could not match valid md5 hash")
+	require.False(t, m.matchHash("world"), "Could match invalid md5 hash")
+
+	m = &Matcher{Type: "hash", Algorithm: "crc"}
+	require.NotNil(t, m.CompileMatchers(), "Could compile unknown hash algorithm")
+}
+
+func TestMurmur3(t *testing.T) {
+	require.Equal(t, uint32(0), murmur3([]byte("")), "Could not hash empty data")
+	require.Equal(t, uint32(0x248bfa47), murmur3([]byte("hello")), "Could not hash data")
+	require.Equal(t, uint32(0x2e4ff723), murmur3([]byte("The quick brown fox jumps over the lazy dog")), "Could not hash data")
+}
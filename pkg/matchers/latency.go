@@ -0,0 +1,67 @@
+package matchers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// latencyThreshold is a compiled latency threshold of a matcher
+type latencyThreshold struct {
+	// slower specifies whether the response must be slower than the
+	// duration, or faster otherwise.
+	slower   bool
+	duration time.Duration
+}
+
+// parseLatency parses a latency threshold such as >2s or <500ms
+func parseLatency(value string) (*latencyThreshold, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || (value[0] != '>' && value[0] != '<') {
+		return nil, fmt.Errorf("invalid latency operator: %s", value)
+	}
+
+	duration, err := time.ParseDuration(strings.TrimSpace(value[1:]))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse latency: %s", value)
+	}
+	return &latencyThreshold{slower: value[0] == '>', duration: duration}, nil
+}
+
+// matchLatency matches the duration of a request against the latency thresholds
+func (m *Matcher) matchLatency(data map[string]interface{}) bool {
+	seconds, ok := data["duration"].(float64)
+	if !ok {
+		return false
+	}
+	duration := time.Duration(seconds * float64(time.Second))
+
+	// Iterate over all the thresholds accepted as valid
+	for i, threshold := range m.latencyCompiled {
+		matched := duration < threshold.duration
+		if threshold.slower {
+			matched = duration > threshold.duration
+		}
+
+		if !matched {
+			// If we are in an AND request and a match failed,
+			// return false as the AND condition fails on any single mismatch.
+			if m.condition == ANDCondition {
+				return false
+			}
+			// Continue with the flow since its an OR Condition.
+			continue
+		}
+
+		// If the condition was an OR, return on the first match.
+		if m.condition == ORCondition {
+			return true
+		}
+
+		// If we are at the end of the thresholds, return with true
+		if len(m.latencyCompiled)-1 == i {
+			return true
+		}
+	}
+	return false
+}
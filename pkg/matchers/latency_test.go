@@ -0,0 +1,19 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyMatcher(t *testing.T) {
+	m := &Matcher{Type: "latency", Condition: "and", Latency: []string{">1s", "<3s"}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile matcher")
+
+	require.True(t, m.matchLatency(map[string]interface{}{"duration": 2.0}), "Could not match latency in range")
+	require.False(t, m.matchLatency(map[string]interface{}{"duration": 0.5}), "Could match latency below range")
+	require.False(t, m.matchLatency(map[string]interface{}{}), "Could match without duration")
+
+	m = &Matcher{Type: "latency", Latency: []string{"2s"}}
+	require.NotNil(t, m.CompileMatchers(), "Could compile latency without operator")
+}
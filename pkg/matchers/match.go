@@ -8,8 +8,25 @@ import (
 	"github.com/miekg/dns"
 )
 
-// Match matches a http response again a given matcher
-func (m *Matcher) Match(resp *http.Response, body, headers string) bool {
+// Match matches a http response again a given matcher.
+//
+// data contains additional values about the response, such as the
+// request timings, which are made available to the dsl matchers. It also
+// contains the html title and meta values and the decoded json web tokens
+// when a matcher asks for them.
+func (m *Matcher) Match(resp *http.Response, body, headers string, data map[string]interface{}) bool {
+	// Skip the responses of the mime types the matcher doesn't apply to
+	if len(m.ContentType) > 0 && !MatchContentType(m.ContentType, GetContentType(resp, body)) {
+		return false
+	}
+
+	// Match the html title or meta values or the decoded json web tokens
+	// parsed from the response
+	part := m.part
+	if part == TitlePart || part == MetaPart || part == JWTPart {
+		body, part = m.getDataValue(data), BodyPart
+	}
+
 	switch m.matcherType {
 	case StatusMatcher:
 		return m.matchStatusCode(resp.StatusCode)
@@ -17,9 +34,9 @@ func (m *Matcher) Match(resp *http.Response, body, headers string) bool {
 		return m.matchSizeCode(len(body))
 	case WordsMatcher:
 		// Match the parts as required for word check
-		if m.part == BodyPart {
+		if part == BodyPart {
 			return m.matchWords(body)
-		} else if m.part == HeaderPart {
+		} else if part == HeaderPart {
 			return m.matchWords(headers)
 		} else {
 			if !m.matchWords(headers) {
@@ -29,9 +46,9 @@ func (m *Matcher) Match(resp *http.Response, body, headers string) bool {
 		}
 	case RegexMatcher:
 		// Match the parts as required for regex check
-		if m.part == BodyPart {
+		if part == BodyPart {
 			return m.matchRegex(body)
-		} else if m.part == HeaderPart {
+		} else if part == HeaderPart {
 			return m.matchRegex(headers)
 		} else {
 			if m.matchRegex(headers) {
@@ -41,9 +58,9 @@ func (m *Matcher) Match(resp *http.Response, body, headers string) bool {
 		}
 	case BinaryMatcher:
 		// Match the parts as required for binary characters check
-		if m.part == BodyPart {
+		if part == BodyPart {
 			return m.matchBinary(body)
-		} else if m.part == HeaderPart {
+		} else if part == HeaderPart {
 			return m.matchBinary(headers)
 		} else {
 			if !m.matchBinary(headers) {
@@ -51,9 +68,18 @@ func (m *Matcher) Match(resp *http.Response, body, headers string) bool {
 			}
 			return m.matchBinary(body)
 		}
+	case HashMatcher:
+		// Match the digest of the parts as required
+		if part == HeaderPart {
+			return m.matchHash(headers)
+		}
+		return m.matchHash(body)
+	case LatencyMatcher:
+		// Match the duration of the request
+		return m.matchLatency(data)
 	case DSLMatcher:
 		// Match complex query
-		return m.matchDSL(httpToMap(resp, body, headers))
+		return m.matchDSL(httpToMap(resp, body, headers, data))
 	}
 	return false
 }
@@ -73,6 +99,9 @@ func (m *Matcher) MatchDNS(msg *dns.Msg) bool {
 	case BinaryMatcher:
 		// Match binary characters check
 		return m.matchBinary(msg.String())
+	case HashMatcher:
+		// Match the digest of the message
+		return m.matchHash(msg.String())
 	case DSLMatcher:
 		// Match complex query
 		return m.matchDSL(dnsToMap(msg))
@@ -114,6 +143,14 @@ func (m *Matcher) matchSizeCode(length int) bool {
 
 // matchWords matches a word check against an HTTP Response/Headers.
 func (m *Matcher) matchWords(corpus string) bool {
+	// Match all the words in a single pass if the automaton is compiled
+	if m.wordsAutomaton != nil {
+		if m.condition == ANDCondition {
+			return m.wordsAutomaton.matchAll(corpus)
+		}
+		return m.wordsAutomaton.matchAny(corpus)
+	}
+
 	// Iterate over all the words accepted as valid
 	for i, word := range m.Words {
 		// Continue if the word doesn't match
@@ -1,6 +1,9 @@
 package matchers
 
 import (
+	"io/ioutil"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -28,3 +31,42 @@ func TestORCondition(t *testing.T) {
 	matched = m.matchWords("c")
 	require.False(t, matched, "Could match invalid OR condition")
 }
+
+func TestMatchDSLJWT(t *testing.T) {
+	m := &Matcher{Type: "dsl", DSL: []string{`jwt_alg == "none"`}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile matcher")
+	require.True(t, m.UsesJWT(), "Could not detect jwt values used by dsl")
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}
+	require.True(t, m.Match(resp, "", "", map[string]interface{}{"jwt_alg": "none"}), "Could not match jwt algorithm")
+	require.False(t, m.Match(resp, "", "", map[string]interface{}{"jwt_alg": "HS256"}), "Could match other jwt algorithm")
+
+	m = &Matcher{Type: "dsl", DSL: []string{"status_code == 200"}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile matcher")
+	require.False(t, m.UsesJWT(), "Could detect jwt values in dsl not using them")
+}
+
+func TestMatchHTMLParts(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	data := map[string]interface{}{"title": "Admin Login", "meta:generator": "WordPress 5.4"}
+
+	tests := []struct {
+		part    string
+		words   []string
+		matched bool
+	}{
+		{"title", []string{"Admin"}, true},
+		{"title", []string{"WordPress"}, false},
+		{"meta:Generator", []string{"WordPress"}, true},
+		{"meta:generator", []string{"Admin"}, false},
+		{"meta:description", []string{"WordPress"}, false},
+	}
+	for _, test := range tests {
+		m := &Matcher{Type: "word", Part: test.part, Words: test.words}
+		require.Nil(t, m.CompileMatchers(), "Could not compile matcher for part %s", test.part)
+		require.Equal(t, test.matched, m.Match(resp, "body", "", data), "Could not match part %s", test.part)
+	}
+
+	m := &Matcher{Type: "word", Part: "metas", Words: []string{"a"}}
+	require.NotNil(t, m.CompileMatchers(), "Could compile unknown part")
+}
@@ -21,12 +21,31 @@ type Matcher struct {
 	Size []int `yaml:"size,omitempty"`
 	// Words are the words required to be present in the response
 	Words []string `yaml:"words,omitempty"`
+	// WordsFile is a file containing additional words, one per line.
+	//
+	// Relative paths are resolved from the directory of the template.
+	WordsFile string `yaml:"words-file,omitempty"`
+	// wordsAutomaton is the compiled variant of the words
+	wordsAutomaton *wordsAutomaton
 	// Regex are the regex pattern required to be present in the response
 	Regex []string `yaml:"regex,omitempty"`
 	// regexCompiled is the compiled variant
 	regexCompiled []*regexp.Regexp
 	// Binary are the binary characters required to be present in the response
 	Binary []string `yaml:"binary,omitempty"`
+	// Hash are the acceptable digests of the response for hash matchers
+	Hash []string `yaml:"hash,omitempty"`
+	// Algorithm is the hash algorithm used by hash matchers
+	//
+	// Supported algorithms are md5, sha1, sha256 and mmh3.
+	Algorithm string `yaml:"algorithm,omitempty"`
+	// hashAlgorithm is the compiled variant of the algorithm
+	hashAlgorithm HashAlgorithm
+	// Latency are the thresholds of the duration of the request, for
+	// example >2s or <500ms, for latency matchers.
+	Latency []string `yaml:"latency,omitempty"`
+	// latencyCompiled is the compiled variant
+	latencyCompiled []*latencyThreshold
 	// DSL are the dsl queries
 	DSL []string `yaml:"dsl,omitempty"`
 	// dslCompiled is the compiled variant
@@ -39,12 +58,21 @@ type Matcher struct {
 	// condition is the condition of the matcher
 	condition ConditionType
 
+	// ContentType optionally restricts the matcher to the responses of
+	// the mime types, such as application/json or image/*, prefixed by !
+	// to exclude them. Responses of other types are not matched.
+	ContentType []string `yaml:"content-type,omitempty"`
+
 	// Part is the part of the request to match
 	//
 	// By default, matching is performed in request body.
 	Part string `yaml:"part,omitempty"`
 	// part is the part of the request to match
 	part Part
+	// metaName is the name of the meta tag to match for meta parts
+	metaName string
+	// dslJWT specifies whether the dsl expressions use the jwt values
+	dslJWT bool
 }
 
 // MatcherType is the type of the matcher specified
@@ -63,16 +91,22 @@ const (
 	SizeMatcher
 	// DSLMatcher matches based upon dsl syntax
 	DSLMatcher
+	// HashMatcher matches responses with a digest of the response
+	HashMatcher
+	// LatencyMatcher matches responses with the duration of the request
+	LatencyMatcher
 )
 
 // MatcherTypes is an table for conversion of matcher type from string.
 var MatcherTypes = map[string]MatcherType{
-	"status": StatusMatcher,
-	"size":   SizeMatcher,
-	"word":   WordsMatcher,
-	"regex":  RegexMatcher,
-	"binary": BinaryMatcher,
-	"dsl":    DSLMatcher,
+	"status":  StatusMatcher,
+	"size":    SizeMatcher,
+	"word":    WordsMatcher,
+	"regex":   RegexMatcher,
+	"binary":  BinaryMatcher,
+	"dsl":     DSLMatcher,
+	"hash":    HashMatcher,
+	"latency": LatencyMatcher,
 }
 
 // ConditionType is the type of condition for matcher
@@ -101,16 +135,49 @@ const (
 	HeaderPart
 	// AllPart matches both response body and headers of the response.
 	AllPart
+	// TitlePart matches the html title of the response.
+	TitlePart
+	// MetaPart matches the content of a html meta tag of the response.
+	MetaPart
+	// JWTPart matches the json web tokens decoded from the response.
+	JWTPart
 )
 
 // PartTypes is an table for conversion of part type from string.
+//
+// Meta parts are specified as meta:<name> and are not part of the table.
 var PartTypes = map[string]Part{
 	"body":   BodyPart,
 	"header": HeaderPart,
 	"all":    AllPart,
+	"title":  TitlePart,
+	"jwt":    JWTPart,
 }
 
+// MetaPartPrefix is the prefix of the parts matching html meta tags.
+const MetaPartPrefix = "meta:"
+
 // GetPart returns the part of the matcher
 func (m *Matcher) GetPart() Part {
 	return m.part
 }
+
+// UsesJWT returns true if the matcher needs the json web tokens decoded
+// from the response, either as its part or through the jwt values, such
+// as jwt_alg, used by its dsl expressions.
+func (m *Matcher) UsesJWT() bool {
+	return m.part == JWTPart || m.dslJWT
+}
+
+// getDataValue returns the html title or meta value, or the decoded json
+// web tokens, to match from the values parsed from the response.
+func (m *Matcher) getDataValue(data map[string]interface{}) string {
+	key := "title"
+	if m.part == MetaPart {
+		key = MetaPartPrefix + m.metaName
+	} else if m.part == JWTPart {
+		key = "jwt"
+	}
+	value, _ := data[key].(string)
+	return value
+}
@@ -9,9 +9,13 @@ import (
 	"github.com/miekg/dns"
 )
 
-func httpToMap(resp *http.Response, body, headers string) (m map[string]interface{}) {
+func httpToMap(resp *http.Response, body, headers string, data map[string]interface{}) (m map[string]interface{}) {
 	m = make(map[string]interface{})
 
+	for k, v := range data {
+		m[k] = v
+	}
+
 	m["content_length"] = resp.ContentLength
 	m["status_code"] = resp.StatusCode
 	for k, v := range resp.Header {
@@ -0,0 +1,57 @@
+package matchers
+
+import (
+	"bufio"
+	"os"
+	"sync"
+)
+
+// wordsFile contains the words of a file along with their automaton
+type wordsFile struct {
+	words []string
+	// automaton matches the words of the file, shared by the matchers
+	// only using the words of the file
+	automaton *wordsAutomaton
+}
+
+// wordsFiles caches the words loaded from files and their automatons so
+// that files shared across templates are only read and compiled once.
+var wordsFiles = struct {
+	sync.Mutex
+	files map[string]*wordsFile
+}{files: make(map[string]*wordsFile)}
+
+// loadWordsFile returns the words from a file containing one word per line.
+func loadWordsFile(path string) (*wordsFile, error) {
+	wordsFiles.Lock()
+	defer wordsFiles.Unlock()
+
+	if file, ok := wordsFiles.files[path]; ok {
+		return file, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if word := scanner.Text(); word != "" {
+			words = append(words, word)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	loaded := &wordsFile{words: words}
+	if len(words) > 0 {
+		loaded.automaton = newWordsAutomaton(words)
+	}
+	wordsFiles.files[path] = loaded
+	return loaded, nil
+}
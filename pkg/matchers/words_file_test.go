@@ -0,0 +1,34 @@
+package matchers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordsFileAutomatonCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-words-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "words.txt")
+	require.Nil(t, ioutil.WriteFile(path, []byte("admin\n\npanel\n"), 0644), "Could not write words file")
+
+	first := &Matcher{Type: "word", WordsFile: path}
+	require.Nil(t, first.CompileMatchers(), "Could not compile matcher")
+	second := &Matcher{Type: "word", WordsFile: path}
+	require.Nil(t, second.CompileMatchers(), "Could not compile matcher")
+	require.Equal(t, []string{"admin", "panel"}, first.Words, "Could not load words file")
+	require.True(t, first.wordsAutomaton == second.wordsAutomaton, "Could not share the automaton of the words file")
+
+	// Matchers with their own words compile their own automaton
+	combined := &Matcher{Type: "word", Words: []string{"login"}, WordsFile: path}
+	require.Nil(t, combined.CompileMatchers(), "Could not compile matcher")
+	require.False(t, combined.wordsAutomaton == first.wordsAutomaton, "Shared the automaton of the words file with other words")
+	require.True(t, combined.matchWords("login page"), "Could not match own words")
+	require.True(t, combined.matchWords("admin page"), "Could not match words of the file")
+	require.Equal(t, []string{"admin", "panel"}, first.Words, "Modified the cached words")
+}
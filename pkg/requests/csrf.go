@@ -0,0 +1,211 @@
+package requests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	retryablehttp "github.com/projectdiscovery/retryablehttp-go"
+	"golang.org/x/net/html"
+)
+
+// DefaultCSRFNames contains the commonly used names of anti-csrf token parameters.
+var DefaultCSRFNames = []string{
+	"csrf",
+	"csrf_token",
+	"csrf-token",
+	"csrfmiddlewaretoken",
+	"_csrf",
+	"_csrf_token",
+	"_token",
+	"authenticity_token",
+	"__RequestVerificationToken",
+	"xsrf",
+	"_xsrf",
+	"xsrf-token",
+}
+
+// CSRFOptions contains the configuration for refreshing anti-csrf
+// tokens before each request is sent.
+type CSRFOptions struct {
+	// Source is the path of the page the token is fetched from.
+	Source string `yaml:"source"`
+	// Names contains the names of the token parameters.
+	//
+	// By default, DefaultCSRFNames is used.
+	Names []string `yaml:"names,omitempty"`
+}
+
+// CSRFToken is an anti-csrf token fetched from the source page.
+type CSRFToken struct {
+	// Name is the name of the token parameter
+	Name string
+	// Value is the value of the token
+	Value string
+	// Cookies are the cookies set by the source page which
+	// the token is bound to.
+	Cookies []*http.Cookie
+}
+
+// getNames returns the token names to look for
+func (c *CSRFOptions) getNames() []string {
+	if len(c.Names) > 0 {
+		return c.Names
+	}
+	return DefaultCSRFNames
+}
+
+// isTokenName returns true if the name is a known token name
+func (c *CSRFOptions) isTokenName(name string) bool {
+	for _, tokenName := range c.getNames() {
+		if strings.EqualFold(name, tokenName) {
+			return true
+		}
+	}
+	return false
+}
+
+// MakeSourceRequest creates the request fetching the page holding the
+// token for a step, sending the headers and cookies of the step so that
+// the token is issued for the same session.
+func (c *CSRFOptions) MakeSourceRequest(baseURL string, step *retryablehttp.Request) (*retryablehttp.Request, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	replacer := newReplacer(map[string]interface{}{
+		"BaseURL":  baseURL,
+		"Hostname": parsed.Hostname(),
+	})
+
+	req, err := http.NewRequest(http.MethodGet, replacer.Replace(c.Source), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Nuclei (@pdiscoveryio)")
+	for header, values := range step.Header {
+		// The body headers and stale tokens of the step don't apply
+		if strings.EqualFold(header, "Content-Type") || strings.EqualFold(header, "Content-Length") || c.isTokenHeader(header) {
+			continue
+		}
+		req.Header[header] = append([]string(nil), values...)
+	}
+	return retryablehttp.FromRequest(req)
+}
+
+// ExtractToken extracts the token from the response of the source page.
+//
+// Hidden form fields are searched first, followed by meta tags.
+func (c *CSRFOptions) ExtractToken(resp *http.Response, body string) (*CSRFToken, bool) {
+	for _, form := range ParseForms(strings.NewReader(body)) {
+		for name := range form.Fields {
+			if c.isTokenName(name) {
+				return &CSRFToken{Name: name, Value: form.Fields.Get(name), Cookies: resp.Cookies()}, true
+			}
+		}
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return nil, false
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+		token := tokenizer.Token()
+		if token.Data != "meta" {
+			continue
+		}
+		if name := getAttribute(token, "name"); c.isTokenName(name) {
+			return &CSRFToken{Name: name, Value: getAttribute(token, "content"), Cookies: resp.Cookies()}, true
+		}
+	}
+}
+
+// ApplyToken replaces the token parameters of a request with a fresh token.
+//
+// Token parameters are detected in the query, the urlencoded body and
+// the headers of the request. Cookies of the source page are merged into
+// the cookies of the request, replacing the cookies with the same name.
+func (c *CSRFOptions) ApplyToken(req *retryablehttp.Request, token *CSRFToken) (*retryablehttp.Request, error) {
+	query := req.URL.Query()
+	if c.replaceValues(query, token.Value) {
+		req.URL.RawQuery = query.Encode()
+	}
+
+	for header := range req.Header {
+		if c.isTokenHeader(header) {
+			req.Header.Set(header, token.Value)
+		}
+	}
+	mergeCookies(req.Header, token.Cookies)
+
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return req, nil
+	}
+	data, err := req.BodyBytes()
+	if err != nil {
+		return nil, err
+	}
+	body, err := url.ParseQuery(string(data))
+	if err != nil || !c.replaceValues(body, token.Value) {
+		return req, nil
+	}
+	req.Request.Body = ioutil.NopCloser(bytes.NewReader([]byte(body.Encode())))
+	return retryablehttp.FromRequest(req.Request)
+}
+
+// isTokenHeader returns true if the header is a token header, with
+// or without the x- prefix
+func (c *CSRFOptions) isTokenHeader(header string) bool {
+	return c.isTokenName(header) || c.isTokenName(strings.TrimPrefix(strings.ToLower(header), "x-"))
+}
+
+// mergeCookies merges cookies into the cookie header, replacing the
+// values of the cookies already set.
+func mergeCookies(header http.Header, cookies []*http.Cookie) {
+	if len(cookies) == 0 {
+		return
+	}
+
+	request := &http.Request{Header: header}
+	var merged []string
+	replaced := make(map[string]struct{})
+	for _, existing := range request.Cookies() {
+		for _, cookie := range cookies {
+			if cookie.Name == existing.Name {
+				existing = cookie
+				replaced[cookie.Name] = struct{}{}
+			}
+		}
+		merged = append(merged, formatCookie(existing))
+	}
+	for _, cookie := range cookies {
+		if _, ok := replaced[cookie.Name]; !ok {
+			merged = append(merged, formatCookie(cookie))
+		}
+	}
+	header.Set("Cookie", strings.Join(merged, "; "))
+}
+
+// formatCookie formats a cookie as sent in the cookie header
+func formatCookie(cookie *http.Cookie) string {
+	return (&http.Cookie{Name: cookie.Name, Value: cookie.Value}).String()
+}
+
+// replaceValues replaces the value of token parameters, returning
+// true if any parameter was replaced.
+func (c *CSRFOptions) replaceValues(values url.Values, value string) bool {
+	var replaced bool
+	for name := range values {
+		if c.isTokenName(name) {
+			values.Set(name, value)
+			replaced = true
+		}
+	}
+	return replaced
+}
@@ -0,0 +1,74 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	retryablehttp "github.com/projectdiscovery/retryablehttp-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractToken(t *testing.T) {
+	c := &CSRFOptions{}
+	resp := &http.Response{Header: http.Header{"Set-Cookie": []string{"session=fresh; Path=/"}}}
+
+	token, ok := c.ExtractToken(resp, testFormPage)
+	require.True(t, ok, "Could not extract token from form")
+	require.Equal(t, "csrf", token.Name, "Could not get token name")
+	require.Equal(t, "token", token.Value, "Could not get token value")
+	require.Len(t, token.Cookies, 1, "Could not get source cookies")
+
+	token, ok = c.ExtractToken(resp, `<html><head><meta name="csrf-token" content="meta"></head></html>`)
+	require.True(t, ok, "Could not extract token from meta tag")
+	require.Equal(t, "meta", token.Value, "Could not get meta token value")
+
+	_, ok = (&CSRFOptions{Names: []string{"other"}}).ExtractToken(resp, testFormPage)
+	require.False(t, ok, "Extracted token with unknown name")
+}
+
+func TestApplyToken(t *testing.T) {
+	c := &CSRFOptions{}
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/login?csrf=stale&q=1", strings.NewReader("csrf=stale&user=admin"))
+	require.Nil(t, err, "Could not create request")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-CSRF-Token", "stale")
+	req.Header.Set("Cookie", "session=stale; theme=dark")
+	step, err := retryablehttp.FromRequest(req)
+	require.Nil(t, err, "Could not create retryable request")
+
+	token := &CSRFToken{Name: "csrf", Value: "fresh", Cookies: []*http.Cookie{
+		{Name: "session", Value: "fresh"},
+		{Name: "bound", Value: "1"},
+	}}
+	applied, err := c.ApplyToken(step, token)
+	require.Nil(t, err, "Could not apply token")
+	require.Equal(t, "fresh", applied.URL.Query().Get("csrf"), "Could not replace query token")
+	require.Equal(t, "1", applied.URL.Query().Get("q"), "Could not keep query parameters")
+	require.Equal(t, "fresh", applied.Header.Get("X-CSRF-Token"), "Could not replace header token")
+	require.Equal(t, []string{"session=fresh; theme=dark; bound=1"}, applied.Header["Cookie"], "Could not merge cookies")
+
+	body, err := applied.BodyBytes()
+	require.Nil(t, err, "Could not read body")
+	require.Equal(t, "csrf=fresh&user=admin", string(body), "Could not replace body token")
+}
+
+func TestMakeSourceRequest(t *testing.T) {
+	c := &CSRFOptions{Source: "{{BaseURL}}/form"}
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/login", strings.NewReader("csrf=stale"))
+	require.Nil(t, err, "Could not create request")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer key")
+	req.Header.Set("Cookie", "session=value")
+	req.Header.Set("X-CSRF-Token", "stale")
+	step, err := retryablehttp.FromRequest(req)
+	require.Nil(t, err, "Could not create retryable request")
+
+	source, err := c.MakeSourceRequest("http://example.com", step)
+	require.Nil(t, err, "Could not make source request")
+	require.Equal(t, "http://example.com/form", source.URL.String(), "Could not replace source url")
+	require.Equal(t, "Bearer key", source.Header.Get("Authorization"), "Could not copy step headers")
+	require.Equal(t, "session=value", source.Header.Get("Cookie"), "Could not copy step cookies")
+	require.Empty(t, source.Header.Get("Content-Type"), "Copied step body headers")
+	require.Empty(t, source.Header.Get("X-CSRF-Token"), "Copied stale step token")
+}
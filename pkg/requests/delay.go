@@ -0,0 +1,65 @@
+package requests
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// requestDelay is the delay waited between the requests of a request block.
+type requestDelay struct {
+	min time.Duration
+	max time.Duration
+}
+
+// parseDelay parses a fixed delay (2s) or a jittered delay range (1s-3s)
+func parseDelay(value string) (*requestDelay, error) {
+	parts := strings.SplitN(value, "-", 2)
+
+	min, err := time.ParseDuration(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse delay: %s", value)
+	}
+	max := min
+	if len(parts) == 2 {
+		max, err = time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse delay: %s", value)
+		}
+	}
+	if min < 0 || max < min {
+		return nil, fmt.Errorf("invalid delay range: %s", value)
+	}
+	return &requestDelay{min: min, max: max}, nil
+}
+
+// get returns the duration to wait, picked randomly in the range if jittered
+func (d *requestDelay) get() time.Duration {
+	if d.max == d.min {
+		return d.min
+	}
+	return d.min + time.Duration(rand.Int63n(int64(d.max-d.min)+1))
+}
+
+// CompileDelay parses the delay of the request, if any.
+func (r *HTTPRequest) CompileDelay() error {
+	if r.Delay == "" {
+		return nil
+	}
+	delay, err := parseDelay(r.Delay)
+	if err != nil {
+		return err
+	}
+	r.delay = delay
+	return nil
+}
+
+// GetDelay returns the duration to wait before sending the next request
+// of the block, or 0 if no delay was specified.
+func (r *HTTPRequest) GetDelay() time.Duration {
+	if r.delay == nil {
+		return 0
+	}
+	return r.delay.get()
+}
@@ -0,0 +1,45 @@
+package requests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDelay(t *testing.T) {
+	tests := []struct {
+		value string
+		min   time.Duration
+		max   time.Duration
+		err   bool
+	}{
+		{value: "2s", min: 2 * time.Second, max: 2 * time.Second},
+		{value: "1s-3s", min: time.Second, max: 3 * time.Second},
+		{value: "500ms - 1s", min: 500 * time.Millisecond, max: time.Second},
+		{value: "3s-1s", err: true},
+		{value: "-1s", err: true},
+		{value: "1s-", err: true},
+		{value: "soon", err: true},
+	}
+	for _, test := range tests {
+		delay, err := parseDelay(test.value)
+		if test.err {
+			require.NotNil(t, err, "Could parse invalid delay %s", test.value)
+			continue
+		}
+		require.Nil(t, err, "Could not parse delay %s", test.value)
+		require.Equal(t, &requestDelay{min: test.min, max: test.max}, delay, "Could not get delay range for %s", test.value)
+	}
+}
+
+func TestGetDelay(t *testing.T) {
+	require.Equal(t, time.Duration(0), (&HTTPRequest{}).GetDelay(), "Could get delay without one specified")
+
+	request := &HTTPRequest{Delay: "1s-3s"}
+	require.Nil(t, request.CompileDelay(), "Could not compile delay")
+	for i := 0; i < 100; i++ {
+		delay := request.GetDelay()
+		require.True(t, delay >= time.Second && delay <= 3*time.Second, "Could get delay out of range: %s", delay)
+	}
+}
@@ -0,0 +1,39 @@
+package requests
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// envRegex matches the {{env "NAME"}} expressions of a request
+var envRegex = regexp.MustCompile(`{{env "([^"{}]+)"}}`)
+
+// EnvValues returns the values replacing the {{env "NAME"}} expressions
+// for the allowed environment variables which are set.
+func EnvValues(allowed []string) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, name := range allowed {
+		if value, ok := os.LookupEnv(name); ok {
+			values[fmt.Sprintf("env %q", name)] = value
+		}
+	}
+	return values
+}
+
+// GetEnvVariables returns the names of the environment variables
+// used by the request with {{env "NAME"}} expressions.
+func (r *HTTPRequest) GetEnvVariables() []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, value := range r.templateValues() {
+		for _, match := range envRegex.FindAllStringSubmatch(value, -1) {
+			if _, ok := seen[match[1]]; ok {
+				continue
+			}
+			seen[match[1]] = struct{}{}
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
@@ -0,0 +1,29 @@
+package requests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvValues(t *testing.T) {
+	os.Setenv("NUCLEI_TEST_KEY", "secret")
+	defer os.Unsetenv("NUCLEI_TEST_KEY")
+	os.Setenv("NUCLEI_TEST_OTHER", "other")
+	defer os.Unsetenv("NUCLEI_TEST_OTHER")
+
+	values := EnvValues([]string{"NUCLEI_TEST_KEY", "NUCLEI_TEST_MISSING"})
+	require.Equal(t, map[string]interface{}{`env "NUCLEI_TEST_KEY"`: "secret"}, values, "Could not get values of allowed variables which are set")
+	require.Equal(t, "Bearer secret", newReplacer(values).Replace(`Bearer {{env "NUCLEI_TEST_KEY"}}`), "Could not replace env expression")
+}
+
+func TestGetEnvVariables(t *testing.T) {
+	r := &HTTPRequest{
+		Path:    []string{`{{BaseURL}}/{{env "TENANT"}}`},
+		Headers: map[string]string{"Authorization": `Bearer {{env "API_KEY"}}`, "X-Tenant": `{{env "TENANT"}}`},
+		Body:    `{"user": "{{env "USER"}}"}`,
+	}
+	require.ElementsMatch(t, []string{"TENANT", "API_KEY", "USER"}, r.GetEnvVariables(), "Could not get env variables of request")
+	require.Empty(t, (&HTTPRequest{Path: []string{"{{BaseURL}}"}}).GetEnvVariables(), "Could get env variables of request without them")
+}
@@ -0,0 +1,229 @@
+package requests
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	retryablehttp "github.com/projectdiscovery/retryablehttp-go"
+	"golang.org/x/net/html"
+)
+
+// FormRequest is a request step built by submitting a html form
+// found in the response of the previous step.
+type FormRequest struct {
+	// ID is the id or name attribute of the form to submit.
+	//
+	// By default, the form at Index is submitted.
+	ID string `yaml:"id,omitempty"`
+	// Index is the position of the form in the page, starting from 0.
+	Index int `yaml:"index,omitempty"`
+	// Fields contains the values to set on the form fields. Values
+	// override the ones parsed from the page, so they can be used
+	// to inject payloads in the submission.
+	Fields map[string]string `yaml:"fields,omitempty"`
+}
+
+// Form is a html form parsed from a response.
+type Form struct {
+	// ID is the id attribute of the form, or name if no id is set.
+	ID string
+	// Action is the URL the form is submitted to.
+	Action string
+	// Method is the method used to submit the form.
+	Method string
+	// Fields contains the names and default values of the form fields.
+	Fields url.Values
+}
+
+// ParseForms parses all the html forms from a response body.
+func ParseForms(body io.Reader) []*Form {
+	var forms []*Form
+	var form *Form
+	var textarea, selectName string
+	var selectHasValue bool
+
+	tokenizer := html.NewTokenizer(body)
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return forms
+		}
+		token := tokenizer.Token()
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if token.Data == "form" {
+				form = &Form{
+					ID:     getAttribute(token, "id"),
+					Action: getAttribute(token, "action"),
+					Method: strings.ToUpper(getAttribute(token, "method")),
+					Fields: make(url.Values),
+				}
+				if form.ID == "" {
+					form.ID = getAttribute(token, "name")
+				}
+				if form.Method == "" {
+					form.Method = http.MethodGet
+				}
+				forms = append(forms, form)
+				continue
+			}
+			if form == nil {
+				continue
+			}
+
+			name := getAttribute(token, "name")
+			switch token.Data {
+			case "input":
+				if name == "" {
+					continue
+				}
+				switch strings.ToLower(getAttribute(token, "type")) {
+				case "submit", "button", "image", "reset", "file":
+					continue
+				case "checkbox", "radio":
+					if !hasAttribute(token, "checked") {
+						continue
+					}
+				}
+				form.Fields.Add(name, getAttribute(token, "value"))
+			case "textarea":
+				textarea = name
+				if name != "" {
+					form.Fields.Set(name, "")
+				}
+			case "select":
+				selectName, selectHasValue = name, false
+			case "option":
+				if selectName == "" {
+					continue
+				}
+				// Use the selected option, or the first one if none is selected
+				if !selectHasValue || hasAttribute(token, "selected") {
+					form.Fields.Set(selectName, getAttribute(token, "value"))
+					selectHasValue = true
+				}
+			}
+		case html.TextToken:
+			if form != nil && textarea != "" {
+				form.Fields.Set(textarea, form.Fields.Get(textarea)+token.Data)
+			}
+		case html.EndTagToken:
+			switch token.Data {
+			case "form":
+				form = nil
+			case "textarea":
+				textarea = ""
+			case "select":
+				selectName = ""
+			}
+		}
+	}
+}
+
+// getAttribute returns the value of an attribute of a html token
+func getAttribute(token html.Token, name string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// hasAttribute returns true if the html token has an attribute
+func hasAttribute(token html.Token, name string) bool {
+	for _, attr := range token.Attr {
+		if attr.Key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// selectForm returns the form the request wants to submit
+func (f *FormRequest) selectForm(forms []*Form) (*Form, error) {
+	if f.ID != "" {
+		for _, form := range forms {
+			if form.ID == f.ID {
+				return form, nil
+			}
+		}
+		return nil, fmt.Errorf("could not find form with id %s", f.ID)
+	}
+	if f.Index < 0 || f.Index >= len(forms) {
+		return nil, fmt.Errorf("could not find form at index %d", f.Index)
+	}
+	return forms[f.Index], nil
+}
+
+// MakeFormRequest creates the request for a form step of the template
+// from the response of the previous step.
+func (r *HTTPRequest) MakeFormRequest(step int, baseURL string, dynamicValues map[string]interface{}, resp *http.Response, body string) (*retryablehttp.Request, error) {
+	if step <= 0 || step >= len(r.Raw) || r.Raw[step].Form == nil {
+		return nil, fmt.Errorf("step %d is not a form step", step)
+	}
+	rawRequest := r.Raw[step]
+	formRequest := rawRequest.Form
+
+	form, err := formRequest.selectForm(ParseForms(strings.NewReader(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the action relative to the page the form was found on
+	action, err := resp.Request.URL.Parse(form.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{
+		"BaseURL":  baseURL,
+		"Hostname": parsed.Hostname(),
+	}
+	for k, v := range dynamicValues {
+		values[k] = v
+	}
+	replacer := newReplacer(values)
+
+	fields := make(url.Values)
+	for name, value := range form.Fields {
+		fields[name] = append([]string(nil), value...)
+	}
+	for name, value := range formRequest.Fields {
+		fields.Set(name, replacer.Replace(value))
+	}
+
+	var req *http.Request
+	if form.Method == http.MethodGet {
+		action.RawQuery = fields.Encode()
+		req, err = http.NewRequest(form.Method, action.String(), nil)
+	} else {
+		req, err = http.NewRequest(form.Method, action.String(), strings.NewReader(fields.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the cookies set by the page so that session bound
+	// tokens in the form stay valid.
+	for _, cookie := range resp.Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	// Override the redirect behaviour if the step asks for it
+	if options, ok := r.getStepRedirectOptions(rawRequest); ok {
+		req = withRedirectOptions(req, options)
+	}
+	return r.fillRequest(req, values)
+}
@@ -0,0 +1,59 @@
+package requests
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testFormPage = `<html><body>
+<form id="search" action="/search"><input name="q"></form>
+<form name="login" action="/login" method="post">
+	<input type="hidden" name="csrf" value="token">
+	<input type="text" name="username">
+	<input type="checkbox" name="remember">
+	<select name="lang"><option value="en">en</option><option value="fr" selected>fr</option></select>
+	<textarea name="note">hello</textarea>
+	<input type="submit" name="go" value="Login">
+</form>
+</body></html>`
+
+func TestParseForms(t *testing.T) {
+	forms := ParseForms(strings.NewReader(testFormPage))
+	require.Len(t, forms, 2, "Could not parse all forms")
+
+	login := forms[1]
+	require.Equal(t, "login", login.ID, "Could not use name as form id")
+	require.Equal(t, http.MethodPost, login.Method, "Could not parse form method")
+	require.Equal(t, url.Values{
+		"csrf":     []string{"token"},
+		"username": []string{""},
+		"lang":     []string{"fr"},
+		"note":     []string{"hello"},
+	}, login.Fields, "Could not parse form fields")
+}
+
+func TestMakeFormRequest(t *testing.T) {
+	r := &HTTPRequest{Raw: []*RawRequest{
+		{Request: "GET / HTTP/1.1"},
+		{Form: &FormRequest{ID: "login", Fields: map[string]string{"username": "{{Hostname}}"}}},
+	}}
+
+	page, _ := http.NewRequest(http.MethodGet, "https://example.com/account/", nil)
+	resp := &http.Response{Request: page, Header: http.Header{"Set-Cookie": []string{"session=1"}}}
+
+	req, err := r.MakeFormRequest(1, "https://example.com", nil, resp, testFormPage)
+	require.Nil(t, err, "Could not make form request")
+	require.Equal(t, "https://example.com/login", req.URL.String(), "Could not resolve form action")
+	require.Equal(t, "session=1", req.Header.Get("Cookie"), "Could not send page cookies")
+
+	body, err := req.BodyBytes()
+	require.Nil(t, err, "Could not read form body")
+
+	values, _ := url.ParseQuery(string(body))
+	require.Equal(t, "example.com", values.Get("username"), "Could not set form field")
+	require.Equal(t, "token", values.Get("csrf"), "Could not keep hidden field")
+}
@@ -0,0 +1,113 @@
+package requests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	retryablehttp "github.com/projectdiscovery/retryablehttp-go"
+)
+
+// GRPCRequest contains a grpc call to make on the paths of a request.
+type GRPCRequest struct {
+	// Service is the fully qualified name of the service, eg. grpc.health.v1.Health
+	Service string `yaml:"service"`
+	// Method is the name of the method of the service to call
+	Method string `yaml:"method"`
+	// Message is the hex encoded serialized protobuf message to send.
+	// Messages written in json with a descriptor set are not supported.
+	Message string `yaml:"message,omitempty"`
+	// Web specifies whether the call is sent using grpc-web over http/1.1
+	// instead of native grpc over http/2.
+	Web bool `yaml:"web,omitempty"`
+}
+
+// CompileGRPC validates the grpc call of the request, if any.
+func (r *HTTPRequest) CompileGRPC() error {
+	if r.GRPC == nil {
+		return nil
+	}
+	if r.GRPC.Service == "" || r.GRPC.Method == "" {
+		return errors.New("grpc service and method must be specified")
+	}
+	// The body of the call is the framed message
+	if r.Body != "" {
+		return errors.New("grpc calls can't have a body, use the message instead")
+	}
+	// Messages using variables can only be checked once replaced
+	if !strings.Contains(r.GRPC.Message, "{{") {
+		if _, err := hex.DecodeString(r.GRPC.Message); err != nil {
+			return fmt.Errorf("could not decode grpc message: %s", err)
+		}
+	}
+	return nil
+}
+
+// makeGRPCRequest creates a grpc call on a base url
+func (r *HTTPRequest) makeGRPCRequest(baseURL string, values map[string]interface{}) (*retryablehttp.Request, error) {
+	message, err := hex.DecodeString(newReplacer(values).Replace(r.GRPC.Message))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode grpc message: %s", err)
+	}
+
+	URL := strings.TrimSuffix(baseURL, "/") + "/" + r.GRPC.Service + "/" + r.GRPC.Method
+	req, err := http.NewRequest(http.MethodPost, URL, bytes.NewReader(MakeGRPCFrame(message)))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.GRPC.Web {
+		req.Header.Set("Content-Type", "application/grpc-web+proto")
+		req.Header.Set("X-Grpc-Web", "1")
+	} else {
+		req.Header.Set("Content-Type", "application/grpc")
+		req.Header.Set("TE", "trailers")
+	}
+
+	request, err := r.fillRequest(req, values)
+	if err != nil {
+		return nil, err
+	}
+	// Connection specific headers are not allowed in http/2
+	request.Header.Del("Connection")
+	request.Close = false
+	return request, nil
+}
+
+// MakeGRPCFrame frames an uncompressed message for the grpc wire format.
+func MakeGRPCFrame(message []byte) []byte {
+	frame := make([]byte, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(message)))
+	copy(frame[5:], message)
+	return frame
+}
+
+// GRPCFrame is a length prefixed frame of a grpc body.
+type GRPCFrame struct {
+	// Trailer specifies whether the frame contains the trailers of a grpc-web response
+	Trailer bool
+	// Data contains the content of the frame
+	Data []byte
+}
+
+// ParseGRPCFrames parses the frames of a grpc body, ignoring any
+// incomplete frame at the end of the body.
+func ParseGRPCFrames(body []byte) []*GRPCFrame {
+	var frames []*GRPCFrame
+	for len(body) >= 5 {
+		length := binary.BigEndian.Uint32(body[1:5])
+		if uint64(len(body)-5) < uint64(length) {
+			break
+		}
+		frames = append(frames, &GRPCFrame{
+			Trailer: body[0]&0x80 != 0,
+			Data:    body[5 : 5+length],
+		})
+		body = body[5+length:]
+	}
+	return frames
+}
@@ -0,0 +1,32 @@
+package requests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCFrames(t *testing.T) {
+	body := append(MakeGRPCFrame([]byte{0x08, 0x01}), 0x80, 0, 0, 0, 14)
+	body = append(body, []byte("grpc-status:12")...)
+
+	frames := ParseGRPCFrames(body)
+	require.Len(t, frames, 2, "Could not parse frames")
+	require.False(t, frames[0].Trailer, "Could not parse message frame")
+	require.Equal(t, []byte{0x08, 0x01}, frames[0].Data, "Could not parse message frame")
+	require.True(t, frames[1].Trailer, "Could not parse trailer frame")
+	require.Equal(t, "grpc-status:12", string(frames[1].Data), "Could not parse trailer frame")
+
+	require.Len(t, ParseGRPCFrames(body[:len(body)-1]), 1, "Could parse incomplete frame")
+}
+
+func TestCompileGRPC(t *testing.T) {
+	request := &HTTPRequest{GRPC: &GRPCRequest{Service: "grpc.health.v1.Health", Method: "Check", Message: "0a00"}}
+	require.Nil(t, request.CompileGRPC(), "Could not compile grpc call")
+
+	request.Body = "test"
+	require.NotNil(t, request.CompileGRPC(), "Could compile grpc call with a body")
+
+	request = &HTTPRequest{GRPC: &GRPCRequest{Service: "grpc.health.v1.Health", Method: "Check", Message: "zz"}}
+	require.NotNil(t, request.CompileGRPC(), "Could compile invalid grpc message")
+}
@@ -38,8 +38,63 @@ type HTTPRequest struct {
 	Redirects bool `yaml:"redirects,omitempty"`
 	// MaxRedirects is the maximum number of redirects that should be followed.
 	MaxRedirects int `yaml:"max-redirects,omitempty"`
+	// RedirectCookies specifies whether the cookies set by the responses
+	// of a redirect chain are sent on the following requests of the chain,
+	// to the hosts and paths they are set for.
+	RedirectCookies bool `yaml:"redirect-cookies,omitempty"`
+	// GRPC optionally calls a grpc method on each of the paths
+	// of the request instead of sending the request itself.
+	GRPC *GRPCRequest `yaml:"grpc,omitempty"`
 	// Raw contains raw requests
-	Raw []string `yaml:"raw,omitempty"`
+	Raw []*RawRequest `yaml:"raw,omitempty"`
+	// Discovered specifies whether the request is also sent to the urls
+	// discovered from the targets by crawling, seeding and analyzing
+	// their javascript files, instead of only to the targets.
+	Discovered bool `yaml:"discovered,omitempty"`
+	// Delay optionally waits between the requests of the block, either
+	// a fixed duration (2s) or a range picked randomly from (1s-3s).
+	Delay string `yaml:"delay,omitempty"`
+	// delay is the parsed delay of the request
+	delay *requestDelay
+	// CSRF optionally refreshes the anti-csrf tokens of the requests
+	// from a source page before each request is sent.
+	CSRF *CSRFOptions `yaml:"csrf,omitempty"`
+	// ContentType optionally restricts the matchers and extractors to the
+	// responses of the mime types, such as application/json or image/*,
+	// prefixed by ! to exclude them.
+	ContentType []string `yaml:"content-type,omitempty"`
+	// Spoof optionally sets the ip spoofing headers using a named profile.
+	Spoof string `yaml:"spoof,omitempty"`
+	// spoof is the spoof profile of the request
+	spoof SpoofProfile
+}
+
+// RawRequest is a single raw request step of a template.
+//
+// It can either be written as a plain string containing the request,
+// or as a map with the request and options specific to this step.
+type RawRequest struct {
+	// Request contains the raw request
+	Request string `yaml:"request"`
+	// Redirects optionally overrides whether redirects should be followed for this step.
+	Redirects *bool `yaml:"redirects,omitempty"`
+	// MaxRedirects optionally overrides the maximum number of redirects for this step.
+	//
+	// Setting it without redirects follows the redirects of this step.
+	MaxRedirects int `yaml:"max-redirects,omitempty"`
+	// Form optionally builds this step by submitting a form from the
+	// response of the previous step instead of using a raw request.
+	Form *FormRequest `yaml:"form,omitempty"`
+}
+
+// UnmarshalYAML unmarshals a raw request from either a string or a map.
+func (r *RawRequest) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&r.Request); err == nil {
+		return nil
+	}
+
+	type rawRequest RawRequest
+	return unmarshal((*rawRequest)(r))
 }
 
 // GetMatchersCondition returns the condition for the matcher
@@ -52,8 +107,13 @@ func (r *HTTPRequest) SetMatchersCondition(condition matchers.ConditionType) {
 	r.matchersCondition = condition
 }
 
-// MakeHTTPRequest creates a *http.Request from a request configuration
-func (r *HTTPRequest) MakeHTTPRequest(baseURL string) ([]*retryablehttp.Request, error) {
+// MakeHTTPRequest creates a *http.Request from a request configuration.
+//
+// dynamicValues optionally contains additional values to replace in the request.
+//
+// Steps which are built from the response of a previous step, such as
+// form steps, are returned as nil and must be created with MakeFormRequest.
+func (r *HTTPRequest) MakeHTTPRequest(baseURL string, dynamicValues map[string]interface{}) ([]*retryablehttp.Request, error) {
 	parsed, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, err
@@ -64,6 +124,9 @@ func (r *HTTPRequest) MakeHTTPRequest(baseURL string) ([]*retryablehttp.Request,
 		"BaseURL":  baseURL,
 		"Hostname": hostname,
 	}
+	for k, v := range dynamicValues {
+		values[k] = v
+	}
 
 	if len(r.Raw) > 0 {
 		return r.makeHTTPRequestFromRaw(baseURL, values)
@@ -79,6 +142,15 @@ func (r *HTTPRequest) makeHTTPRequestFromModel(baseURL string, values map[string
 		// Replace the dynamic variables in the URL if any
 		URL := replacer.Replace(path)
 
+		if r.GRPC != nil {
+			request, err := r.makeGRPCRequest(URL, values)
+			if err != nil {
+				return nil, err
+			}
+			requests = append(requests, request)
+			continue
+		}
+
 		// Build a request on the specified URL
 		req, err := http.NewRequest(r.Method, URL, nil)
 		if err != nil {
@@ -99,9 +171,15 @@ func (r *HTTPRequest) makeHTTPRequestFromModel(baseURL string, values map[string
 // makeHTTPRequestFromRaw creates a *http.Request from a raw request
 func (r *HTTPRequest) makeHTTPRequestFromRaw(baseURL string, values map[string]interface{}) (requests []*retryablehttp.Request, err error) {
 	replacer := newReplacer(values)
-	for _, raw := range r.Raw {
+	for _, rawRequest := range r.Raw {
+		// Form steps are created from the previous response during execution
+		if rawRequest.Form != nil {
+			requests = append(requests, nil)
+			continue
+		}
+
 		// Add trailing line
-		raw += "\n"
+		raw := rawRequest.Request + "\n"
 
 		// Replace the dynamic variables in the URL if any
 		raw = replacer.Replace(raw)
@@ -124,6 +202,11 @@ func (r *HTTPRequest) makeHTTPRequestFromRaw(baseURL string, values map[string]i
 		// copy headers
 		req.Header = parsedReq.Header.Clone()
 
+		// Override the redirect behaviour if the step asks for it
+		if options, ok := r.getStepRedirectOptions(rawRequest); ok {
+			req = withRedirectOptions(req, options)
+		}
+
 		request, err := r.fillRequest(req, values)
 		if err != nil {
 			return nil, err
@@ -135,11 +218,44 @@ func (r *HTTPRequest) makeHTTPRequestFromRaw(baseURL string, values map[string]i
 	return requests, nil
 }
 
+// UsesVariable returns true if a {{name}} variable is used in the request.
+func (r *HTTPRequest) UsesVariable(name string) bool {
+	variable := "{{" + name + "}}"
+
+	for _, value := range r.templateValues() {
+		if strings.Contains(value, variable) {
+			return true
+		}
+	}
+	return false
+}
+
+// templateValues returns the values of the request in which
+// variables are replaced.
+func (r *HTTPRequest) templateValues() []string {
+	values := append([]string{r.Body}, r.Path...)
+	for _, value := range r.Headers {
+		values = append(values, value)
+	}
+	if r.GRPC != nil {
+		values = append(values, r.GRPC.Message)
+	}
+	for _, raw := range r.Raw {
+		values = append(values, raw.Request)
+		if raw.Form != nil {
+			for _, value := range raw.Form.Fields {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
 func (r *HTTPRequest) fillRequest(req *http.Request, values map[string]interface{}) (*retryablehttp.Request, error) {
 	replacer := newReplacer(values)
 	// Check if the user requested a request body
 	if r.Body != "" {
-		req.Body = ioutil.NopCloser(strings.NewReader(r.Body))
+		req.Body = ioutil.NopCloser(strings.NewReader(replacer.Replace(r.Body)))
 	}
 
 	// Set the header values requested
@@ -163,3 +279,9 @@ func (r *HTTPRequest) fillRequest(req *http.Request, values map[string]interface
 
 	return retryablehttp.FromRequest(req)
 }
+
+// MatchesContentType returns true if the matchers and extractors of the
+// request apply to a response of a mime type.
+func (r *HTTPRequest) MatchesContentType(contentType string) bool {
+	return matchers.MatchContentType(r.ContentType, contentType)
+}
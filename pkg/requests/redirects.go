@@ -0,0 +1,50 @@
+package requests
+
+import (
+	"context"
+	"net/http"
+)
+
+// redirectOptionsKey is the context key for the redirect options of a request
+type redirectOptionsKey struct{}
+
+// RedirectOptions contains the redirect behaviour for a single request.
+type RedirectOptions struct {
+	// Follow specifies whether redirects should be followed.
+	Follow bool
+	// Max is the maximum number of redirects that should be followed.
+	Max int
+}
+
+// withRedirectOptions returns a copy of the request carrying the redirect options.
+func withRedirectOptions(req *http.Request, options *RedirectOptions) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), redirectOptionsKey{}, options))
+}
+
+// getStepRedirectOptions returns the redirect options of a raw request
+// step, if it overrides the ones of the template.
+//
+// A step only setting the maximum number of redirects follows them,
+// and a step only setting whether to follow them uses the maximum
+// number of redirects of the template.
+func (r *HTTPRequest) getStepRedirectOptions(step *RawRequest) (*RedirectOptions, bool) {
+	if step.Redirects == nil && step.MaxRedirects <= 0 {
+		return nil, false
+	}
+
+	options := &RedirectOptions{Follow: true, Max: r.MaxRedirects}
+	if step.Redirects != nil {
+		options.Follow = *step.Redirects
+	}
+	if step.MaxRedirects > 0 {
+		options.Max = step.MaxRedirects
+	}
+	return options, true
+}
+
+// GetRedirectOptions returns the redirect options overriding the
+// template defaults for a request, if any.
+func GetRedirectOptions(ctx context.Context) (*RedirectOptions, bool) {
+	options, ok := ctx.Value(redirectOptionsKey{}).(*RedirectOptions)
+	return options, ok
+}
@@ -0,0 +1,66 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStepRedirectOptions(t *testing.T) {
+	follow, noFollow := true, false
+	r := &HTTPRequest{Redirects: false, MaxRedirects: 5}
+
+	_, ok := r.getStepRedirectOptions(&RawRequest{})
+	require.False(t, ok, "Overrode redirects of step without options")
+
+	options, ok := r.getStepRedirectOptions(&RawRequest{MaxRedirects: 2})
+	require.True(t, ok, "Could not override redirects with max-redirects only")
+	require.Equal(t, &RedirectOptions{Follow: true, Max: 2}, options, "Could not follow redirects with max-redirects only")
+
+	options, ok = r.getStepRedirectOptions(&RawRequest{Redirects: &follow})
+	require.True(t, ok, "Could not override redirects")
+	require.Equal(t, &RedirectOptions{Follow: true, Max: 5}, options, "Could not use template max-redirects")
+
+	options, _ = r.getStepRedirectOptions(&RawRequest{Redirects: &noFollow, MaxRedirects: 2})
+	require.Equal(t, &RedirectOptions{Follow: false, Max: 2}, options, "Could not disable redirects of step")
+}
+
+func TestMakeHTTPRequestStepRedirects(t *testing.T) {
+	r := &HTTPRequest{Raw: []*RawRequest{
+		{Request: "GET / HTTP/1.1\nHost: {{Hostname}}\n\n"},
+		{Request: "GET /next HTTP/1.1\nHost: {{Hostname}}\n\n", MaxRedirects: 3},
+	}}
+
+	reqs, err := r.MakeHTTPRequest("https://example.com", nil)
+	require.Nil(t, err, "Could not make requests")
+	require.Len(t, reqs, 2, "Could not make all the steps")
+
+	_, ok := GetRedirectOptions(reqs[0].Context())
+	require.False(t, ok, "Overrode redirects of step without options")
+	options, ok := GetRedirectOptions(reqs[1].Context())
+	require.True(t, ok, "Could not override redirects of step")
+	require.Equal(t, &RedirectOptions{Follow: true, Max: 3}, options, "Could not apply max-redirects of step")
+}
+
+func TestMakeFormRequestStepRedirects(t *testing.T) {
+	follow := true
+	r := &HTTPRequest{MaxRedirects: 5, Raw: []*RawRequest{
+		{Request: "GET / HTTP/1.1\nHost: {{Hostname}}\n\n"},
+		{Form: &FormRequest{ID: "search"}, MaxRedirects: 2},
+		{Form: &FormRequest{ID: "search"}, Redirects: &follow},
+	}}
+	page, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	resp := &http.Response{Request: page, Header: http.Header{}}
+
+	req, err := r.MakeFormRequest(1, "https://example.com", nil, resp, `<form id="search" action="/search"></form>`)
+	require.Nil(t, err, "Could not make form request")
+	options, ok := GetRedirectOptions(req.Context())
+	require.True(t, ok, "Could not override redirects of form step")
+	require.Equal(t, &RedirectOptions{Follow: true, Max: 2}, options, "Could not apply max-redirects of form step")
+
+	req, err = r.MakeFormRequest(2, "https://example.com", nil, resp, `<form id="search" action="/search"></form>`)
+	require.Nil(t, err, "Could not make form request")
+	options, _ = GetRedirectOptions(req.Context())
+	require.Equal(t, &RedirectOptions{Follow: true, Max: 5}, options, "Could not use template max-redirects for form step")
+}
@@ -0,0 +1,86 @@
+package requests
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+)
+
+// SpoofHeaders are the headers commonly trusted by applications
+// to get the ip address of the client.
+var SpoofHeaders = []string{
+	"X-Forwarded-For",
+	"X-Real-IP",
+	"X-Originating-IP",
+	"X-Remote-IP",
+	"X-Remote-Addr",
+	"X-Client-IP",
+	"Client-IP",
+	"True-Client-IP",
+}
+
+// SpoofProfile returns the ip address to send in the spoof headers
+type SpoofProfile func() string
+
+// SpoofProfiles is a table of the named ip spoofing profiles.
+//
+// The profiles generating random addresses rotate the address
+// with each request sent.
+var SpoofProfiles = map[string]SpoofProfile{
+	"loopback": func() string {
+		return "127.0.0.1"
+	},
+	"localhost": func() string {
+		return "localhost"
+	},
+	"private": func() string {
+		return fmt.Sprintf("10.%d.%d.%d", rand.Intn(256), rand.Intn(256), 1+rand.Intn(254))
+	},
+	"random": func() string {
+		return fmt.Sprintf("%d.%d.%d.%d", 1+rand.Intn(223), rand.Intn(256), rand.Intn(256), 1+rand.Intn(254))
+	},
+}
+
+// GetSpoofProfile returns a spoof profile by name, or nil if the name is empty.
+func GetSpoofProfile(name string) (SpoofProfile, error) {
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := SpoofProfiles[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown spoof profile specified: %s", name)
+	}
+	return profile, nil
+}
+
+// CompileSpoof parses the spoof profile of the request, if any.
+func (r *HTTPRequest) CompileSpoof() error {
+	profile, err := GetSpoofProfile(r.Spoof)
+	if err != nil {
+		return err
+	}
+	r.spoof = profile
+	return nil
+}
+
+// ApplySpoof sets the spoof headers on a request using the profile of
+// the request, or the fallback profile if the request doesn't specify one.
+//
+// Headers already present on the request are left untouched.
+func (r *HTTPRequest) ApplySpoof(header http.Header, fallback SpoofProfile) {
+	profile := r.spoof
+	if profile == nil {
+		profile = fallback
+	}
+	if profile == nil {
+		return
+	}
+
+	value := profile()
+	for _, name := range SpoofHeaders {
+		if header.Get(name) == "" {
+			header.Set(name, value)
+		}
+	}
+}
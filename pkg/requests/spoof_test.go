@@ -0,0 +1,42 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSpoofProfile(t *testing.T) {
+	profile, err := GetSpoofProfile("")
+	require.Nil(t, err, "Could not get empty spoof profile")
+	require.Nil(t, profile, "Could get profile for empty name")
+
+	profile, err = GetSpoofProfile("Loopback")
+	require.Nil(t, err, "Could not get spoof profile")
+	require.Equal(t, "127.0.0.1", profile(), "Could not get loopback address")
+
+	_, err = GetSpoofProfile("unknown")
+	require.NotNil(t, err, "Could get unknown spoof profile")
+}
+
+func TestApplySpoof(t *testing.T) {
+	request := &HTTPRequest{Spoof: "localhost"}
+	require.Nil(t, request.CompileSpoof(), "Could not compile spoof profile")
+
+	header := http.Header{}
+	header.Set("X-Forwarded-For", "10.0.0.1")
+	request.ApplySpoof(header, SpoofProfiles["loopback"])
+	require.Equal(t, "10.0.0.1", header.Get("X-Forwarded-For"), "Could not keep existing header")
+	require.Equal(t, "localhost", header.Get("X-Real-IP"), "Could not use request profile")
+
+	header = http.Header{}
+	(&HTTPRequest{}).ApplySpoof(header, SpoofProfiles["loopback"])
+	for _, name := range SpoofHeaders {
+		require.Equal(t, "127.0.0.1", header.Get(name), "Could not use fallback profile for %s", name)
+	}
+
+	header = http.Header{}
+	(&HTTPRequest{}).ApplySpoof(header, nil)
+	require.Empty(t, header, "Could set headers without a profile")
+}
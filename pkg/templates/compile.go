@@ -1,7 +1,7 @@
 package templates
 
 import (
-	"os"
+	"path/filepath"
 
 	"github.com/projectdiscovery/nuclei/pkg/matchers"
 	"gopkg.in/yaml.v2"
@@ -11,20 +11,42 @@ import (
 func ParseTemplate(file string) (*Template, error) {
 	template := &Template{}
 
-	f, err := os.Open(file)
+	data, err := readTemplateFile(file)
 	if err != nil {
 		return nil, err
 	}
 
-	err = yaml.NewDecoder(f).Decode(template)
+	err = yaml.Unmarshal(data, template)
 	if err != nil {
-		f.Close()
 		return nil, err
 	}
-	f.Close()
+	if err = compileTemplate(template, file); err != nil {
+		return nil, err
+	}
+	setProvenance(template, file)
+	return template, nil
+}
+
+// compileTemplate compiles the requests, matchers and extractors of
+// a template read from a file.
+func compileTemplate(template *Template, file string) error {
+	var err error
 
 	// Compile the matchers and the extractors for http requests
 	for _, request := range template.RequestsHTTP {
+		if err = request.CompileDelay(); err != nil {
+			return err
+		}
+		if err = request.CompileSpoof(); err != nil {
+			return err
+		}
+		if err = request.CompileGRPC(); err != nil {
+			return err
+		}
+		if err = matchers.ValidateContentTypes(request.ContentType); err != nil {
+			return err
+		}
+
 		// Get the condition between the matchers
 		condition, ok := matchers.ConditionTypes[request.MatchersCondition]
 		if !ok {
@@ -34,14 +56,15 @@ func ParseTemplate(file string) (*Template, error) {
 		}
 
 		for _, matcher := range request.Matchers {
+			resolveWordsFile(matcher, file)
 			if err = matcher.CompileMatchers(); err != nil {
-				return nil, err
+				return err
 			}
 		}
 
 		for _, extractor := range request.Extractors {
 			if err := extractor.CompileExtractors(); err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
@@ -57,17 +80,25 @@ func ParseTemplate(file string) (*Template, error) {
 		}
 
 		for _, matcher := range request.Matchers {
+			resolveWordsFile(matcher, file)
 			if err = matcher.CompileMatchers(); err != nil {
-				return nil, err
+				return err
 			}
 		}
 
 		for _, extractor := range request.Extractors {
 			if err := extractor.CompileExtractors(); err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
+	return nil
+}
 
-	return template, nil
+// resolveWordsFile resolves the words file of a matcher relative
+// to the directory of the template file.
+func resolveWordsFile(matcher *matchers.Matcher, file string) {
+	if matcher.WordsFile != "" && !filepath.IsAbs(matcher.WordsFile) {
+		matcher.WordsFile = filepath.Join(filepath.Dir(file), matcher.WordsFile)
+	}
 }
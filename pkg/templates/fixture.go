@@ -0,0 +1,61 @@
+package templates
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FixtureSuffix is the suffix of the fixture file of a template,
+// replacing the .yaml extension of the template.
+const FixtureSuffix = ".fixture.yaml"
+
+// Fixture contains the canned responses a template is tested against
+// along with the expected results.
+type Fixture struct {
+	// Responses are the responses served to the template
+	Responses []*FixtureResponse `yaml:"responses"`
+	// Matched specifies whether the template is expected to match
+	Matched bool `yaml:"matched"`
+	// Extracted contains values which are expected to be extracted
+	Extracted []string `yaml:"extracted,omitempty"`
+}
+
+// FixtureResponse is a canned response served for a path.
+type FixtureResponse struct {
+	// Method optionally restricts the response to a request method
+	Method string `yaml:"method,omitempty"`
+	// Path is the path the response is served on
+	Path string `yaml:"path"`
+	// Status is the status code of the response, 200 by default
+	Status int `yaml:"status,omitempty"`
+	// Headers contains the headers of the response
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Body is the body of the response
+	Body string `yaml:"body,omitempty"`
+}
+
+// IsFixture returns true if a path is the fixture file of a template
+func IsFixture(path string) bool {
+	return strings.HasSuffix(path, FixtureSuffix)
+}
+
+// GetFixturePath returns the path of the fixture file of a template
+func GetFixturePath(path string) string {
+	return strings.TrimSuffix(path, ".yaml") + FixtureSuffix
+}
+
+// LoadFixture loads a fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := &Fixture{}
+	if err := yaml.Unmarshal(data, fixture); err != nil {
+		return nil, err
+	}
+	return fixture, nil
+}
@@ -0,0 +1,51 @@
+package templates
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFixture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-fixture-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	templatePath := filepath.Join(dir, "panel.yaml")
+	fixturePath := GetFixturePath(templatePath)
+	require.Equal(t, filepath.Join(dir, "panel.fixture.yaml"), fixturePath, "Could not get fixture path")
+	require.True(t, IsFixture(fixturePath), "Could not detect fixture file")
+	require.False(t, IsFixture(templatePath), "Detected template as fixture")
+
+	err = ioutil.WriteFile(fixturePath, []byte(`responses:
+  - path: /login
+    method: POST
+    status: 302
+    headers:
+      Location: /home
+  - path: /
+    body: "<title>Panel</title>"
+matched: true
+extracted:
+  - Panel
+`), 0644)
+	require.Nil(t, err, "Could not write fixture file")
+
+	fixture, err := LoadFixture(fixturePath)
+	require.Nil(t, err, "Could not load fixture")
+	require.True(t, fixture.Matched, "Could not load matched")
+	require.Equal(t, []string{"Panel"}, fixture.Extracted, "Could not load extracted values")
+	require.Len(t, fixture.Responses, 2, "Could not load responses")
+	require.Equal(t, &FixtureResponse{
+		Path:    "/login",
+		Method:  "POST",
+		Status:  302,
+		Headers: map[string]string{"Location": "/home"},
+	}, fixture.Responses[0], "Could not load response")
+
+	_, err = LoadFixture(filepath.Join(dir, "missing.fixture.yaml"))
+	require.NotNil(t, err, "Could not fail on missing fixture")
+}
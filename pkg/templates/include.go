@@ -0,0 +1,114 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxIncludeDepth is the maximum depth of nested includes
+const maxIncludeDepth = 10
+
+// includeRegex matches `key: !include file` and `- !include file` lines
+var includeRegex = regexp.MustCompile(`^(\s*)(-\s+)?([^#\s-][^:]*:\s+)?!include\s+(\S+)\s*$`)
+
+// readTemplateFile reads a template file, replacing the !include
+// directives with the content of the included files.
+//
+// Included paths are resolved relative to the including file. An include
+// used as a map value nests the included document under the key, while an
+// include used as a list item either adds the included document as an item,
+// or adds all of its items if the included document is a list.
+func readTemplateFile(file string) ([]byte, error) {
+	return readIncludeFile(file, 0)
+}
+
+func readIncludeFile(file string, depth int) ([]byte, error) {
+	if depth > maxIncludeDepth {
+		return nil, fmt.Errorf("maximum include depth exceeded in %s", file)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(data, []byte("!include")) {
+		return data, nil
+	}
+
+	builder := &strings.Builder{}
+	for _, line := range strings.Split(string(data), "\n") {
+		matches := includeRegex.FindStringSubmatch(line)
+		if matches == nil {
+			builder.WriteString(line)
+			builder.WriteRune('\n')
+			continue
+		}
+		indent, item, key, path := matches[1], matches[2], matches[3], matches[4]
+		if item == "" && key == "" {
+			builder.WriteString(line)
+			builder.WriteRune('\n')
+			continue
+		}
+
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(file), path)
+		}
+		included, err := readIncludeFile(path, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("could not include %s: %s", path, err)
+		}
+		lines := includedLines(included)
+
+		switch {
+		case key != "":
+			// Nest the document under the key, which may start a list item
+			builder.WriteString(indent + item + strings.TrimSpace(key) + "\n")
+			keyIndent := indent + strings.Repeat(" ", len(item)) + "  "
+			writeIndented(builder, lines, keyIndent, keyIndent)
+		case len(lines) > 0 && strings.HasPrefix(lines[0], "-"):
+			// Add all the items of the included list
+			writeIndented(builder, lines, indent, indent)
+		default:
+			// Add the document as a single item
+			writeIndented(builder, lines, indent+"- ", indent+"  ")
+		}
+	}
+	return []byte(builder.String()), nil
+}
+
+// includedLines returns the lines of an included document without
+// the document markers and surrounding blank lines.
+func includedLines(data []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "---" || line == "..." {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// writeIndented writes lines with the first line using a different indent
+func writeIndented(builder *strings.Builder, lines []string, first, rest string) {
+	for i, line := range lines {
+		if i == 0 {
+			builder.WriteString(first)
+		} else if line != "" {
+			builder.WriteString(rest)
+		}
+		builder.WriteString(line)
+		builder.WriteRune('\n')
+	}
+}
@@ -0,0 +1,53 @@
+package templates
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTemplateFileIncludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-include-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"common/headers.yaml":  "X-Test: a\n",
+		"common/matchers.yaml": "- type: status\n  status:\n    - 200\n- type: word\n  words:\n    - b\n",
+		"common/matcher.yaml":  "type: word\nwords:\n  - c\n",
+		"template.yaml": `id: include
+requests:
+  - headers: !include common/headers.yaml
+    matchers:
+      - !include common/matchers.yaml
+      - !include common/matcher.yaml
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.Nil(t, os.MkdirAll(filepath.Dir(path), 0755), "Could not create directory")
+		require.Nil(t, ioutil.WriteFile(path, []byte(content), 0644), "Could not write file")
+	}
+
+	data, err := readTemplateFile(filepath.Join(dir, "template.yaml"))
+	require.Nil(t, err, "Could not read template with includes")
+	require.Equal(t, `id: include
+requests:
+  - headers:
+      X-Test: a
+    matchers:
+      - type: status
+        status:
+          - 200
+      - type: word
+        words:
+          - b
+      - type: word
+        words:
+          - c
+
+`, string(data), "Could not replace includes")
+}
@@ -0,0 +1,77 @@
+package templates
+
+import "strings"
+
+// Metadata contains the metadata of a parsed template.
+type Metadata struct {
+	// ID is the unique id for the template
+	ID string `json:"id"`
+	// Version is the version of the template, if any
+	Version string `json:"version,omitempty"`
+	// Path is the path of the template file
+	Path string `json:"path"`
+	// Commit is the git commit of the template file, if any
+	Commit string `json:"commit,omitempty"`
+	// Deprecated specifies whether the template is deprecated
+	Deprecated bool `json:"deprecated,omitempty"`
+	// ReplacedBy is the id of the template replacing it, if any
+	ReplacedBy string `json:"replaced_by,omitempty"`
+	// Name is the name of the template
+	Name string `json:"name"`
+	// Author is the name of the author of the template
+	Author string `json:"author"`
+	// Severity is the severity of the template
+	Severity string `json:"severity,omitempty"`
+	// Tags contains the tags of the template
+	Tags []string `json:"tags,omitempty"`
+	// Protocols contains the protocols the template sends requests with
+	Protocols []string `json:"protocols"`
+	// Requests contains the number of requests sent by the template
+	// for each protocol
+	Requests map[string]int `json:"requests"`
+}
+
+// GetMetadata returns the metadata of a template parsed from a path.
+func (t *Template) GetMetadata(path string) *Metadata {
+	metadata := &Metadata{
+		ID:         t.ID,
+		Version:    t.Version,
+		Path:       path,
+		Commit:     t.Commit,
+		Deprecated: t.Deprecated,
+		ReplacedBy: t.ReplacedBy,
+		Name:       t.Info.Name,
+		Author:     t.Info.Author,
+		Severity:   t.Info.Severity,
+		Tags:       t.Info.GetTags(),
+		Protocols:  []string{},
+		Requests:   make(map[string]int),
+	}
+
+	if len(t.RequestsHTTP) > 0 {
+		metadata.Protocols = append(metadata.Protocols, "http")
+		for _, request := range t.RequestsHTTP {
+			if len(request.Raw) > 0 {
+				metadata.Requests["http"] += len(request.Raw)
+			} else {
+				metadata.Requests["http"] += len(request.Path)
+			}
+		}
+	}
+	if len(t.RequestsDNS) > 0 {
+		metadata.Protocols = append(metadata.Protocols, "dns")
+		metadata.Requests["dns"] = len(t.RequestsDNS)
+	}
+	return metadata
+}
+
+// GetTags returns the comma separated tags of a template as a list.
+func (i *Info) GetTags() []string {
+	var tags []string
+	for _, tag := range strings.Split(i.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
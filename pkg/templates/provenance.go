@@ -0,0 +1,103 @@
+package templates
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// gitCommits caches the head commit of the git repositories of the
+// templates, by directory of the repository.
+var gitCommits = struct {
+	sync.Mutex
+	commits map[string]string
+}{commits: make(map[string]string)}
+
+// setProvenance records the file a template was parsed from along with
+// the head commit of the git repository containing it, if any.
+func setProvenance(template *Template, file string) {
+	template.Path = file
+	template.Commit = getGitCommit(file)
+}
+
+// getGitCommit returns the head commit of the git repository containing
+// a file, or an empty string if the file is not in a repository.
+func getGitCommit(file string) string {
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return ""
+	}
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		if _, err := os.Stat(gitDir); err == nil {
+			gitCommits.Lock()
+			defer gitCommits.Unlock()
+
+			commit, ok := gitCommits.commits[dir]
+			if !ok {
+				commit = readGitHead(gitDir)
+				gitCommits.commits[dir] = commit
+			}
+			return commit
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readGitHead reads the commit of the head of a git directory without
+// requiring git, resolving the branch from the loose or packed refs.
+//
+// A .git file pointing to the actual git directory, as created for
+// worktrees and submodules, is followed.
+func readGitHead(gitDir string) string {
+	if data, err := ioutil.ReadFile(gitDir); err == nil {
+		target := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(gitDir), target)
+		}
+		gitDir = target
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(head, "ref: ") {
+		return head
+	}
+	ref := strings.TrimPrefix(head, "ref: ")
+
+	// Worktrees keep their refs in the common git directory
+	commonDir := gitDir
+	if data, err := ioutil.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = filepath.Join(gitDir, strings.TrimSpace(string(data)))
+	}
+	for _, dir := range []string{gitDir, commonDir} {
+		if data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+
+	file, err := os.Open(filepath.Join(commonDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0]
+		}
+	}
+	return ""
+}
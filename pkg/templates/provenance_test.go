@@ -0,0 +1,27 @@
+package templates
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadGitHead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-provenance-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	gitDir := filepath.Join(dir, ".git")
+	require.Nil(t, os.MkdirAll(filepath.Join(gitDir, "refs", "heads"), 0755), "Could not create directory")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644), "Could not write file")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(gitDir, "packed-refs"), []byte("# pack-refs with: peeled\n1111111111111111111111111111111111111111 refs/heads/main\n"), 0644), "Could not write file")
+	require.Equal(t, "1111111111111111111111111111111111111111", readGitHead(gitDir), "Could not read packed ref")
+
+	require.Nil(t, ioutil.WriteFile(filepath.Join(gitDir, "refs", "heads", "main"), []byte("2222222222222222222222222222222222222222\n"), 0644), "Could not write file")
+	require.Equal(t, "2222222222222222222222222222222222222222", readGitHead(gitDir), "Could not read loose ref")
+
+	require.Equal(t, "", readGitHead(filepath.Join(dir, "missing")), "Could not handle missing git directory")
+}
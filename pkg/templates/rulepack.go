@@ -0,0 +1,227 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/pkg/extractors"
+	"github.com/projectdiscovery/nuclei/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/pkg/requests"
+)
+
+// Authors of the templates generated from the supported rule packs
+const (
+	RulePackGitleaks   = "gitleaks"
+	RulePackTruffleHog = "trufflehog"
+)
+
+// RulePackRule is a secret detection rule of a rule pack
+type RulePackRule struct {
+	// ID is the name of the rule
+	ID string
+	// Regex is the regex matching the secrets
+	Regex string
+}
+
+// ParseRulePack parses a secret detection rule pack into a template
+// scanning the responses of the targets for the secrets of the rules.
+//
+// Gitleaks configurations (.toml) and TruffleHog rule files (.json, a map
+// of rule names to regexes) are supported. Each rule becomes a named regex
+// matcher and an extractor of the generated template, which also scans
+// the urls discovered from the targets. The ids of the rules whose regex
+// can't be compiled are returned instead of failing the pack.
+func ParseRulePack(file string) (*Template, []string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var author string
+	var rules []*RulePackRule
+	switch filepath.Ext(file) {
+	case ".toml":
+		author = RulePackGitleaks
+		rules, err = parseGitleaksRules(string(data))
+	case ".json":
+		author = RulePackTruffleHog
+		rules, err = parseTruffleHogRules(data)
+	default:
+		return nil, nil, fmt.Errorf("unknown rule pack format: %s", file)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	request := &requests.HTTPRequest{
+		Method:            "GET",
+		Path:              []string{"{{BaseURL}}"},
+		MatchersCondition: "or",
+		Discovered:        true,
+	}
+	var skipped []string
+	for _, rule := range rules {
+		if _, err := regexp.Compile(rule.Regex); err != nil {
+			skipped = append(skipped, rule.ID)
+			continue
+		}
+		request.Matchers = append(request.Matchers, &matchers.Matcher{Type: "regex", Name: rule.ID, Regex: []string{rule.Regex}})
+		request.Extractors = append(request.Extractors, &extractors.Extractor{Type: "regex", Name: rule.ID, Regex: []string{rule.Regex}})
+	}
+	if len(request.Matchers) == 0 {
+		return nil, skipped, fmt.Errorf("no valid rules found in rule pack: %s", file)
+	}
+
+	template := &Template{
+		ID: "rule-pack-" + name,
+		Info: Info{
+			Name:     name + " rule pack",
+			Author:   author,
+			Severity: SeverityHigh,
+			Tags:     "secrets," + author,
+		},
+		RequestsHTTP: []*requests.HTTPRequest{request},
+	}
+	if err := compileTemplate(template, file); err != nil {
+		return nil, skipped, err
+	}
+	setProvenance(template, file)
+	return template, skipped, nil
+}
+
+// parseTruffleHogRules parses the rules of a TruffleHog rule file,
+// sorted by name.
+func parseTruffleHogRules(data []byte) ([]*RulePackRule, error) {
+	values := make(map[string]string)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	var rules []*RulePackRule
+	for name, regex := range values {
+		rules = append(rules, &RulePackRule{ID: name, Regex: regex})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, nil
+}
+
+// parseGitleaksRules parses the [[rules]] tables of a Gitleaks
+// configuration.
+//
+// Only the subset of toml used by the configurations is supported. The
+// description of a rule is used as its id for older configurations
+// without ids, and rules without a regex are skipped.
+func parseGitleaksRules(data string) ([]*RulePackRule, error) {
+	var rules []*RulePackRule
+	var rule *RulePackRule
+	var description string
+	addRule := func() {
+		if rule != nil && rule.Regex != "" {
+			if rule.ID == "" {
+				rule.ID = description
+			}
+			rules = append(rules, rule)
+		}
+		rule, description = nil, ""
+	}
+
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Tables other than the rules, such as allowlists, end a rule
+		if strings.HasPrefix(line, "[") {
+			addRule()
+			if line == "[[rules]]" {
+				rule = &RulePackRule{}
+			}
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if !strings.HasPrefix(value, `"`) && !strings.HasPrefix(value, "'") {
+			continue
+		}
+		value, next, err := parseTOMLString(value, lines, i)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse line %d: %s", i+1, err)
+		}
+		i = next
+		if rule == nil {
+			continue
+		}
+		switch key {
+		case "id":
+			rule.ID = value
+		case "description":
+			description = value
+		case "regex":
+			rule.Regex = value
+		}
+	}
+	addRule()
+	return rules, nil
+}
+
+// parseTOMLString parses a toml string value starting a line, which can
+// continue on the following lines for multi-line strings. It returns the
+// string and the index of the line it ends on.
+func parseTOMLString(value string, lines []string, i int) (string, int, error) {
+	for _, delimiter := range []string{"'''", `"""`} {
+		if !strings.HasPrefix(value, delimiter) {
+			continue
+		}
+		value = strings.TrimPrefix(value, delimiter)
+		// A newline immediately following the opening delimiter is trimmed
+		multiline := value == ""
+		for !strings.Contains(value, delimiter) {
+			if i++; i >= len(lines) {
+				return "", i, fmt.Errorf("unterminated multi-line string")
+			}
+			if multiline {
+				value, multiline = lines[i], false
+			} else {
+				value += "\n" + lines[i]
+			}
+		}
+		value = value[:strings.Index(value, delimiter)]
+		if delimiter == `"""` {
+			unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(value, "\n", `\n`) + `"`)
+			return unquoted, i, err
+		}
+		return value, i, nil
+	}
+
+	if strings.HasPrefix(value, "'") {
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", i, fmt.Errorf("unterminated literal string")
+		}
+		return value[1 : end+1], i, nil
+	}
+	for end := 1; end < len(value); end++ {
+		switch value[end] {
+		case '\\':
+			end++
+		case '"':
+			unquoted, err := strconv.Unquote(value[:end+1])
+			return unquoted, i, err
+		}
+	}
+	return "", i, fmt.Errorf("unterminated basic string")
+}
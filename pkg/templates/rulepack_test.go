@@ -0,0 +1,62 @@
+package templates
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitleaksRules(t *testing.T) {
+	rules, err := parseGitleaksRules(`title = "gitleaks config"
+
+[[rules]]
+id = "aws-access-token"
+description = "AWS Access Token"
+regex = '''(A3T[A-Z0-9]|AKIA)[A-Z0-9]{16}'''
+keywords = [
+    "akia",
+]
+
+[[rules]]
+description = "Slack Token"
+regex = "xox[baprs]-\\d+"
+
+[rules.allowlist]
+regex = '''ignored'''
+
+[[rules]]
+id = "multi-line"
+regex = '''
+secret-[a-z]+'''
+
+[[rules]]
+id = "path-only"
+path = '''\.pem$'''
+`)
+	require.Nil(t, err, "Could not parse rules")
+	require.Equal(t, []*RulePackRule{
+		{ID: "aws-access-token", Regex: `(A3T[A-Z0-9]|AKIA)[A-Z0-9]{16}`},
+		{ID: "Slack Token", Regex: `xox[baprs]-\d+`},
+		{ID: "multi-line", Regex: `secret-[a-z]+`},
+	}, rules, "Could not get correct rules")
+}
+
+func TestParseRulePack(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-rulepack-*")
+	require.Nil(t, err, "Could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "trufflehog.json")
+	require.Nil(t, ioutil.WriteFile(file, []byte(`{"Slack Token": "xox[baprs]-[0-9]+", "Invalid": "(?<=a)b"}`), 0644), "Could not write file")
+
+	template, skipped, err := ParseRulePack(file)
+	require.Nil(t, err, "Could not parse rule pack")
+	require.Equal(t, []string{"Invalid"}, skipped, "Could not skip invalid rule")
+	require.Equal(t, "rule-pack-trufflehog", template.ID, "Could not get template id")
+	require.Len(t, template.RequestsHTTP[0].Matchers, 1, "Could not get matchers")
+	require.Equal(t, "Slack Token", template.RequestsHTTP[0].Matchers[0].Name, "Could not get matcher name")
+	require.Len(t, template.RequestsHTTP[0].Extractors, 1, "Could not get extractors")
+}
@@ -0,0 +1,34 @@
+package templates
+
+import (
+	"sort"
+	"strings"
+)
+
+// severityRanks contains the rank of each severity, higher being more important.
+var severityRanks = map[string]int{
+	SeverityCritical: 4,
+	SeverityHigh:     3,
+	SeverityMedium:   2,
+	SeverityLow:      1,
+	SeverityInfo:     0,
+}
+
+// SeverityRank returns the rank of a severity. Unknown severities
+// have the lowest rank.
+func SeverityRank(severity string) int {
+	return severityRanks[strings.ToLower(strings.TrimSpace(severity))]
+}
+
+// SortByPriority sorts the templates so that the templates with the
+// highest priority run first, followed by the most severe ones.
+//
+// Templates with the same priority and severity keep their order.
+func SortByPriority(templates []*Template) {
+	sort.SliceStable(templates, func(i, j int) bool {
+		if templates[i].Info.Priority != templates[j].Info.Priority {
+			return templates[i].Info.Priority > templates[j].Info.Priority
+		}
+		return SeverityRank(templates[i].Info.Severity) > SeverityRank(templates[j].Info.Severity)
+	})
+}
@@ -0,0 +1,39 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortByPriority(t *testing.T) {
+	list := []*Template{
+		{ID: "info", Info: Info{Severity: SeverityInfo}},
+		{ID: "high", Info: Info{Severity: SeverityHigh}},
+		{ID: "unknown", Info: Info{Severity: "unknown"}},
+		{ID: "prioritized-low", Info: Info{Severity: SeverityLow, Priority: 1}},
+		{ID: "critical", Info: Info{Severity: " Critical "}},
+		{ID: "high-second", Info: Info{Severity: SeverityHigh}},
+		{ID: "prioritized-most", Info: Info{Severity: SeverityInfo, Priority: 2}},
+	}
+	SortByPriority(list)
+
+	var ids []string
+	for _, template := range list {
+		ids = append(ids, template.ID)
+	}
+	require.Equal(t, []string{
+		"prioritized-most",
+		"prioritized-low",
+		"critical",
+		"high",
+		"high-second",
+		"info",
+		"unknown",
+	}, ids, "Could not sort templates by priority and severity")
+}
+
+func TestSeverityRank(t *testing.T) {
+	require.Greater(t, SeverityRank(SeverityCritical), SeverityRank(SeverityHigh), "Could not rank critical above high")
+	require.Equal(t, SeverityRank(SeverityInfo), SeverityRank("unknown"), "Could not rank unknown severity lowest")
+}
@@ -8,12 +8,30 @@ import (
 type Template struct {
 	// ID is the unique id for the template
 	ID string `yaml:"id"`
+	// Version optionally identifies the revision of the template,
+	// carried into the results to trace them to the template.
+	Version string `yaml:"version,omitempty"`
+	// Deprecated specifies whether the template is deprecated and
+	// should no longer be used.
+	Deprecated bool `yaml:"deprecated,omitempty"`
+	// ReplacedBy optionally is the id of the template replacing
+	// a deprecated template.
+	ReplacedBy string `yaml:"replaced-by,omitempty"`
 	// Info contains information about the template
 	Info Info `yaml:"info"`
 	// RequestHTTP contains the http request to make in the template
 	RequestsHTTP []*requests.HTTPRequest `yaml:"requests"`
 	// RequestDNS contains the dns request to make in the template
 	RequestsDNS []*requests.DNSRequest `yaml:"dns"`
+	// MaxRequests optionally limits the number of requests the
+	// template sends to each host.
+	MaxRequests int `yaml:"max-requests,omitempty"`
+
+	// Path is the file the template was parsed from
+	Path string `yaml:"-"`
+	// Commit is the head commit of the git repository containing
+	// the template file, if any.
+	Commit string `yaml:"-"`
 }
 
 // Info contains information about the request template
@@ -24,11 +42,18 @@ type Info struct {
 	Author string `yaml:"author"`
 	// Severity optionally describes the severity of the template
 	Severity string `yaml:"severity,omitempty"`
+	// Tags optionally contains comma separated tags for the template
+	Tags string `yaml:"tags,omitempty"`
+	// Priority optionally orders the template before others when running
+	// templates by priority. Higher values run first.
+	Priority int `yaml:"priority,omitempty"`
 }
 
 // Levels of severity for a request template
 const (
-	SeverityHigh   = "high"
-	SeverityMedium = "medium"
-	SeverityLow    = "low"
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
 )